@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/gateway"
+)
+
+// inputFlags are the flags naming the input files, shared by the subcommands.
+type inputFlags struct {
+	system   string
+	bank     string
+	profiles string
+	workers  int
+}
+
+func (f *inputFlags) register(fs *flag.FlagSet, required string) {
+	fs.StringVar(&f.system, "system", "", "Path to the system transactions CSV file, or - for standard input"+required)
+	fs.StringVar(&f.bank, "bank", "", "Comma-separated list of paths to bank statement CSV files, optionally as path=profile"+required)
+	fs.StringVar(&f.profiles, "profiles", "", "Path to a YAML or JSON file of bank statement profiles")
+	fs.IntVar(&f.workers, "parse-workers", 0, "How many bank statement files to parse at once (0 for one per CPU)")
+}
+
+// inputs are the input sources and the repository reading them.
+type inputs struct {
+	system      *domain.Source // nil without -system
+	banks       []domain.Source
+	assignments map[string]string // bank statement path to its explicitly chosen profile
+	profiles    *gateway.ProfileSet
+	repo        *gateway.CSVTransactionRepository
+}
+
+// load creates the repository reading the input files, with any extra options. Its only
+// error is a bank profiles file that cannot be loaded.
+func (f *inputFlags) load(opts ...gateway.Option) (inputs, error) {
+	in := inputs{assignments: make(map[string]string)}
+	switch f.system {
+	case "":
+	case "-":
+		source := domain.ReaderSource("stdin", os.Stdin)
+		in.system = &source
+	default:
+		source := domain.FileSource(f.system)
+		in.system = &source
+	}
+
+	// Split bank files string into a slice, peeling off explicit "path=profile" assignments
+	repoOpts := []gateway.Option{gateway.WithParseWorkers(f.workers)}
+	if f.bank != "" {
+		for _, spec := range strings.Split(f.bank, ",") {
+			path, profile, hasProfile := strings.Cut(spec, "=")
+			if hasProfile {
+				repoOpts = append(repoOpts, gateway.WithProfileAssignment(path, profile))
+				in.assignments[path] = profile
+			}
+			in.banks = append(in.banks, domain.FileSource(path))
+		}
+	}
+
+	if f.profiles != "" {
+		profiles, err := gateway.LoadProfiles(f.profiles)
+		if err != nil {
+			return in, err
+		}
+		in.profiles = profiles
+		repoOpts = append(repoOpts, gateway.WithProfiles(profiles))
+	}
+
+	in.repo = gateway.NewCSVTransactionRepository(append(repoOpts, opts...)...)
+	return in, nil
+}
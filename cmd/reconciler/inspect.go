@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// fileSummary describes the content of one input file.
+type fileSummary struct {
+	File      string          `json:"file"`
+	Kind      string          `json:"kind"` // "system" or "bank"
+	Rows      int             `json:"rows"` // transactions, not counting balance rows
+	FirstDate string          `json:"first_date,omitempty"`
+	LastDate  string          `json:"last_date,omitempty"`
+	Totals    []typeTotal     `json:"totals"`
+	Opening   *domain.Decimal `json:"opening_balance,omitempty"`
+	Closing   *domain.Decimal `json:"closing_balance,omitempty"`
+}
+
+// typeTotal is the count and unsigned total of the transactions of one type and currency.
+type typeTotal struct {
+	Type     domain.TransactionType `json:"type"`
+	Currency domain.Currency        `json:"currency"`
+	Count    int                    `json:"count"`
+	Amount   domain.Decimal         `json:"amount"`
+}
+
+// runInspect prints, for each input file, its row count, date range and totals by type.
+func runInspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "inspect", "Print row counts, date ranges and totals by type for each input file.")
+	var inputFlags inputFlags
+	inputFlags.register(fs, "")
+	fs.Parse(args)
+
+	if inputFlags.system == "" && inputFlags.bank == "" {
+		fmt.Println("Error: -system or -bank is required.")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	in, err := inputFlags.load()
+	if err != nil {
+		log.Fatalf("Error loading bank profiles: %v", err)
+	}
+
+	ctx := context.Background()
+	var summaries []fileSummary
+	if in.system != nil {
+		txs, err := in.repo.GetSystemTransactions(ctx, *in.system)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", in.system.Name, err)
+		}
+		summary := fileSummary{File: in.system.Name, Kind: "system"}
+		for _, tx := range txs {
+			summary.add(tx.TransactionTime, tx.Type, tx.Currency, tx.Amount)
+		}
+		summaries = append(summaries, summary.finish())
+	}
+	for _, source := range in.banks {
+		txs, err := in.repo.GetBankTransactions(ctx, []domain.Source{source})
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", source.Name, err)
+		}
+		balances, err := in.repo.GetBankBalances(ctx, []domain.Source{source})
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", source.Name, err)
+		}
+		summary := fileSummary{File: source.Name, Kind: "bank"}
+		for _, tx := range txs {
+			summary.add(tx.Date, tx.Type, tx.Currency, tx.NormalizedAmount)
+		}
+		for _, balance := range balances {
+			summary.Opening, summary.Closing = balance.Opening, balance.Closing
+		}
+		summaries = append(summaries, summary.finish())
+	}
+
+	output, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to generate JSON report: %v", err)
+	}
+	fmt.Println(string(output))
+}
+
+// add counts one transaction towards the summary.
+func (s *fileSummary) add(date time.Time, txType domain.TransactionType, currency domain.Currency, amount domain.Decimal) {
+	s.Rows++
+	day := date.Format(time.DateOnly)
+	if s.FirstDate == "" || day < s.FirstDate {
+		s.FirstDate = day
+	}
+	if day > s.LastDate {
+		s.LastDate = day
+	}
+	for i := range s.Totals {
+		if s.Totals[i].Type == txType && s.Totals[i].Currency == currency {
+			s.Totals[i].Count++
+			s.Totals[i].Amount = s.Totals[i].Amount.Add(amount)
+			return
+		}
+	}
+	s.Totals = append(s.Totals, typeTotal{Type: txType, Currency: currency, Count: 1, Amount: amount})
+}
+
+// finish orders the totals by type and currency.
+func (s fileSummary) finish() fileSummary {
+	if s.Totals == nil {
+		s.Totals = make([]typeTotal, 0)
+	}
+	sort.Slice(s.Totals, func(i, j int) bool {
+		if s.Totals[i].Type != s.Totals[j].Type {
+			return s.Totals[i].Type < s.Totals[j].Type
+		}
+		return s.Totals[i].Currency < s.Totals[j].Currency
+	})
+	return s
+}
@@ -1,68 +1,65 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"runtime"
 	"strings"
-	"time"
-
-	"mini-reconciliation/internal/gateway"
-	"mini-reconciliation/internal/usecase"
 )
 
-func main() {
-	// Define command-line flags
-	systemFile := flag.String("system", "", "Path to the system transactions CSV file (required)")
-	bankFilesStr := flag.String("bank", "", "Comma-separated list of paths to bank statement CSV files (required)")
-	startDateStr := flag.String("start", "", "Start date for reconciliation (YYYY-MM-DD) (required)")
-	endDateStr := flag.String("end", "", "End date for reconciliation (YYYY-MM-DD) (required)")
-	flag.Parse()
+// version is the reconciler release, set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
 
-	// Validate required flags
-	if *systemFile == "" || *bankFilesStr == "" || *startDateStr == "" || *endDateStr == "" {
-		fmt.Println("Error: All flags (-system, -bank, -start, -end) are required.")
-		flag.Usage()
-		os.Exit(1)
+func main() {
+	// Without a subcommand the arguments are reconcile flags, as before subcommands existed.
+	command, args := "reconcile", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	// Parse dates
-	startDate, err := time.Parse("2006-01-02", *startDateStr)
-	if err != nil {
-		log.Fatalf("Error parsing start date: %v", err)
+	switch command {
+	case "reconcile":
+		runReconcile(args)
+	case "validate":
+		runValidate(args)
+	case "inspect":
+		runInspect(args)
+	case "serve":
+		if err := runServe(args); err != nil {
+			log.Fatalf("Error %v", err)
+		}
+	case "version":
+		fmt.Printf("reconciler %s (%s)\n", version, runtime.Version())
+	case "help":
+		usage(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", command)
+		usage(os.Stderr)
+		os.Exit(2)
 	}
-	endDate, err := time.Parse("2006-01-02", *endDateStr)
-	if err != nil {
-		log.Fatalf("Error parsing end date: %v", err)
-	}
-
-	// Split bank files string into a slice
-	bankFiles := strings.Split(*bankFilesStr, ",")
-
-	// --- Dependency Injection (Wiring the application) ---
-	// In a larger app, this might be done with a DI container.
-	// Here, we do it manually, which is clear and simple.
+}
 
-	// 1. Create the repository (the outermost layer)
-	csvRepo := gateway.NewCSVTransactionRepository()
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: reconciler <command> [flags]
 
-	// 2. Create the usecase and inject the repository (the core logic layer)
-	reconciliationUseCase := usecase.NewReconciliationUseCase(csvRepo)
+Commands:
+  reconcile  Reconcile system transactions against bank statements (the default)
+  validate   Parse the input files and report schema and row errors, without matching
+  inspect    Print row counts, date ranges and totals by type for each input file
+  serve      Serve reconciliations of uploaded files over HTTP
+  version    Print the reconciler version
 
-	// --- Execute the Usecase ---
-	report, err := reconciliationUseCase.Reconcile(context.Background(), *systemFile, bankFiles, startDate, endDate)
-	if err != nil {
-		log.Fatalf("Reconciliation failed: %v", err)
-	}
+Run "reconciler <command> -h" for the flags of a command.
+`)
+}
 
-	// --- Present the Output ---
-	output, err := json.MarshalIndent(report, "", "  ")
-	if err != nil {
-		log.Fatalf("Failed to generate JSON report: %v", err)
+// commandUsage prints the usage of a subcommand followed by its flags.
+func commandUsage(fs *flag.FlagSet, command, summary string) func() {
+	return func() {
+		fmt.Fprintf(fs.Output(), "Usage: reconciler %s [flags]\n\n%s\n\nFlags:\n", command, summary)
+		fs.PrintDefaults()
 	}
-
-	fmt.Println(string(output))
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/gateway"
+	"mini-reconciliation/internal/usecase"
+)
+
+// matchingFlags are the flags configuring how transactions are matched, shared by the
+// reconcile and serve commands.
+type matchingFlags struct {
+	currency      string
+	fxRates       string
+	settlementLag int
+	businessDays  bool
+	toleranceAbs  string
+	tolerancePct  string
+	aggregateMax  int
+	passes        string
+	explain       bool
+}
+
+func (f *matchingFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.currency, "currency", string(domain.DefaultCurrency), "Reporting currency amounts are compared in")
+	fs.StringVar(&f.fxRates, "fx-rates", "", "Path to a CSV of daily FX rates (date,from,to,rate)")
+	fs.IntVar(&f.settlementLag, "settlement-lag", 0, "Days a bank booking may differ from the system transaction date")
+	fs.BoolVar(&f.businessDays, "business-days", false, "Count only business days (Mon-Fri) towards -settlement-lag")
+	fs.StringVar(&f.toleranceAbs, "tolerance-abs", "0", "Absolute amount difference tolerated when matching, in the reporting currency")
+	fs.StringVar(&f.tolerancePct, "tolerance-pct", "0", "Amount difference tolerated when matching, as a percentage of the system amount")
+	fs.IntVar(&f.aggregateMax, "aggregate-max", 0, "Match one transaction against up to this many on the other side summing to it (0 disables)")
+	fs.StringVar(&f.passes, "passes", "reference,exact,group,aggregate,tolerance", "Comma-separated matching passes, in the order they run")
+	fs.BoolVar(&f.explain, "explain", false, "List every matched pair with the pass, key and confidence of the match")
+}
+
+// options returns the usecase options for the matching flags, deriving fees and reference
+// patterns from the bank profiles and the statements explicitly assigned one.
+func (f *matchingFlags) options(profiles *gateway.ProfileSet, assignments map[string]string) ([]usecase.Option, error) {
+	absTolerance, err := domain.ParseDecimal(f.toleranceAbs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -tolerance-abs: %w", err)
+	}
+	pctTolerance, err := domain.ParseDecimal(f.tolerancePct)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -tolerance-pct: %w", err)
+	}
+
+	opts := []usecase.Option{
+		usecase.WithReportingCurrency(domain.Currency(f.currency)),
+		usecase.WithSettlementLag(f.settlementLag, f.businessDays),
+	}
+	references, err := referenceRules(profiles, assignments)
+	if err != nil {
+		return nil, fmt.Errorf("could not load reference patterns: %w", err)
+	}
+	matchers, err := buildMatchers(strings.Split(f.passes, ","), matcherConfig{
+		aggregateMax: f.aggregateMax,
+		absTolerance: absTolerance,
+		pctTolerance: pctTolerance,
+		fees:         feeSchedule(profiles, assignments),
+		references:   references,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not configure matching passes: %w", err)
+	}
+	opts = append(opts, usecase.WithMatchers(matchers...))
+	if f.explain {
+		opts = append(opts, usecase.WithMatchExplanations())
+	}
+	if f.fxRates != "" {
+		fxRates, err := gateway.LoadFXRates(f.fxRates)
+		if err != nil {
+			return nil, fmt.Errorf("could not load FX rates: %w", err)
+		}
+		opts = append(opts, usecase.WithFXRates(fxRates))
+	}
+	return opts, nil
+}
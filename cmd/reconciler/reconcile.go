@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/gateway"
+	"mini-reconciliation/internal/presenter"
+	"mini-reconciliation/internal/usecase"
+)
+
+// runReconcile reconciles the system transactions against the bank statements and prints
+// the report as JSON.
+func runReconcile(args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "reconcile", "Reconcile system transactions against bank statements.")
+	var inputFlags inputFlags
+	inputFlags.register(fs, " (required)")
+	var matchingFlags matchingFlags
+	matchingFlags.register(fs)
+	overridesFile := fs.String("overrides", "", "Path to a CSV or JSON file of manual match and ignore overrides")
+	balancesFile := fs.String("balances", "", "Path to a CSV of statement opening/closing balances (bank_source,opening_balance,closing_balance)")
+	ledgerOpening := fs.String("ledger-opening", "", "System ledger balance at the start of the period, compared with the bank closing balances")
+	openItemsFile := fs.String("open-items", "", "Path to a JSON file carrying unmatched transactions forward between periods (read at start, written at end)")
+	lenient := fs.Bool("lenient", false, "Skip rows that cannot be parsed, listing them under ingestion_errors, instead of failing")
+	maxRejected := fs.Int("max-rejected", 0, "With -lenient, still fail when more than this many rows are skipped (0 for no limit)")
+	stream := fs.Bool("stream", false, "Stream the inputs and match one date at a time, for statements too large to hold in memory")
+	sortBuffer := fs.Int("sort-buffer", gateway.DefaultSortBuffer, "With -stream, how many transactions to sort in memory before spilling to temporary files")
+	formatName := fs.String("format", string(presenter.FormatJSON), "Output format: json, csv (a directory of exception files), xlsx (a workbook) or html")
+	outPath := fs.String("out", "", "Output file, or directory for -format=csv; JSON and HTML go to stdout when empty")
+	startDateStr := fs.String("start", "", "Start date for reconciliation (YYYY-MM-DD) (required)")
+	endDateStr := fs.String("end", "", "End date for reconciliation (YYYY-MM-DD) (required)")
+	fs.Parse(args)
+
+	// Validate required flags
+	if inputFlags.system == "" || inputFlags.bank == "" || *startDateStr == "" || *endDateStr == "" {
+		fmt.Println("Error: All flags (-system, -bank, -start, -end) are required.")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	format, err := presenter.ParseFormat(*formatName)
+	if err != nil {
+		log.Fatalf("Error parsing -format: %v", err)
+	}
+	if (format == presenter.FormatCSV || format == presenter.FormatXLSX) && *outPath == "" {
+		log.Fatalf("Error: -format=%s requires -out", format)
+	}
+
+	// Parse dates
+	startDate, err := time.Parse("2006-01-02", *startDateStr)
+	if err != nil {
+		log.Fatalf("Error parsing start date: %v", err)
+	}
+	endDate, err := time.Parse("2006-01-02", *endDateStr)
+	if err != nil {
+		log.Fatalf("Error parsing end date: %v", err)
+	}
+
+	var repoOpts []gateway.Option
+	if *lenient {
+		repoOpts = append(repoOpts, gateway.WithLenientParsing(*maxRejected))
+	}
+	if *stream {
+		repoOpts = append(repoOpts, gateway.WithSortBuffer(*sortBuffer))
+	}
+	in, err := inputFlags.load(repoOpts...)
+	if err != nil {
+		log.Fatalf("Error loading bank profiles: %v", err)
+	}
+
+	// --- Dependency Injection (Wiring the application) ---
+	// In a larger app, this might be done with a DI container.
+	// Here, we do it manually, which is clear and simple.
+
+	// 1. Create the repository (the outermost layer)
+	csvRepo := in.repo
+
+	// 2. Create the usecase and inject the repository (the core logic layer)
+	ucOpts, err := matchingFlags.options(in.profiles, in.assignments)
+	if err != nil {
+		log.Fatalf("Error configuring matching: %v", err)
+	}
+	if *lenient {
+		ucOpts = append(ucOpts, usecase.WithIngestionErrors(in.repo))
+	}
+	balanceOpts, err := balanceOptions(*balancesFile, *ledgerOpening, in.profiles)
+	if err != nil {
+		log.Fatalf("Error configuring balance reconciliation: %v", err)
+	}
+	ucOpts = append(ucOpts, balanceOpts...)
+	if *openItemsFile != "" {
+		ucOpts = append(ucOpts, usecase.WithOpenItems(gateway.NewJSONOpenItemStore(*openItemsFile)))
+	}
+	if *overridesFile != "" {
+		overrides, err := gateway.LoadOverrides(*overridesFile)
+		if err != nil {
+			log.Fatalf("Error loading overrides: %v", err)
+		}
+		ucOpts = append(ucOpts, usecase.WithOverrides(overrides))
+	}
+	if *stream {
+		ucOpts = append(ucOpts, usecase.WithDatePartitioning(in.repo))
+	}
+	reconciliationUseCase := usecase.NewReconciliationUseCase(csvRepo, ucOpts...)
+
+	// --- Execute the Usecase ---
+	report, err := reconciliationUseCase.Reconcile(context.Background(), *in.system, in.banks, startDate, endDate)
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	// --- Present the Output ---
+	if err := writeReport(report, format, *outPath); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
+
+// writeReport renders the report in the given format to out, or to stdout when out is empty.
+func writeReport(report *domain.ReconciliationReport, format presenter.Format, out string) error {
+	if format == presenter.FormatCSV {
+		return presenter.WriteCSVBundle(out, report)
+	}
+	write := presenter.WriteJSON
+	switch format {
+	case presenter.FormatXLSX:
+		write = presenter.WriteXLSX
+	case presenter.FormatHTML:
+		write = presenter.WriteHTML
+	}
+	if out == "" {
+		return write(os.Stdout, report)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	defer file.Close()
+	if err := write(file, report); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// balanceOptions enables balance reconciliation when balances are given on the command
+// line or any bank profile reads balance rows from its statements.
+func balanceOptions(balancesFile, ledgerOpening string, profiles *gateway.ProfileSet) ([]usecase.Option, error) {
+	var opts []usecase.Option
+	var balances []domain.StatementBalance
+	enabled := balancesFile != ""
+	if balancesFile != "" {
+		var err error
+		if balances, err = gateway.LoadBalances(balancesFile); err != nil {
+			return nil, err
+		}
+	}
+	if profiles != nil {
+		for _, profile := range profiles.Profiles {
+			enabled = enabled || profile.HasBalanceRows()
+		}
+	}
+	if enabled {
+		opts = append(opts, usecase.WithBalanceReconciliation(balances...))
+	}
+	if ledgerOpening != "" {
+		opening, err := domain.ParseDecimal(ledgerOpening)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -ledger-opening: %w", err)
+		}
+		opts = append(opts, usecase.WithLedgerOpeningBalance(opening))
+	}
+	return opts, nil
+}
+
+// matcherConfig carries the flag values the matching passes are built from.
+type matcherConfig struct {
+	aggregateMax int
+	absTolerance domain.Decimal
+	pctTolerance domain.Decimal
+	fees         []usecase.FeeRule
+	references   []usecase.ReferenceRule
+}
+
+// buildMatchers turns pass names into the matching pipeline. Passes left unconfigured by
+// their flags (aggregate without -aggregate-max, tolerance without tolerances or fees) are skipped.
+func buildMatchers(names []string, cfg matcherConfig) ([]usecase.Matcher, error) {
+	var matchers []usecase.Matcher
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "reference":
+			matchers = append(matchers, usecase.ReferenceMatcher(cfg.references...))
+		case "exact":
+			matchers = append(matchers, usecase.ExactMatcher())
+		case "group":
+			matchers = append(matchers, usecase.GroupMatcher())
+		case "aggregate":
+			if cfg.aggregateMax > 1 {
+				matchers = append(matchers, usecase.AggregateMatcher(cfg.aggregateMax))
+			}
+		case "tolerance":
+			if !cfg.absTolerance.IsZero() || !cfg.pctTolerance.IsZero() || hasFees(cfg.fees) {
+				matchers = append(matchers, usecase.ToleranceMatcher(cfg.absTolerance, cfg.pctTolerance, cfg.fees...))
+			}
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown matching pass %q", name)
+		}
+	}
+	return matchers, nil
+}
+
+// assignedPaths returns the statements explicitly assigned a profile in path order, so the
+// rules derived from them come out the same on every run.
+func assignedPaths(assignments map[string]string) []string {
+	paths := make([]string, 0, len(assignments))
+	for path := range assignments {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// profileSource is a bank source pattern, or an assigned statement's name, with the
+// profile it applies.
+type profileSource struct {
+	bankSource string
+	profile    gateway.BankProfile
+}
+
+// profileSources lists the bank sources the profiles apply to in the order the repository
+// picks a statement's profile: statements explicitly assigned one, then every profile's
+// file pattern. The first entry matching a statement is the profile it was parsed with, so
+// fee and reference rules derived from the list in this order agree with it.
+func profileSources(profiles *gateway.ProfileSet, assignments map[string]string) []profileSource {
+	if profiles == nil {
+		return nil
+	}
+
+	var sources []profileSource
+	for _, path := range assignedPaths(assignments) {
+		if profile, ok := profiles.Lookup(assignments[path]); ok {
+			sources = append(sources, profileSource{bankSource: filepath.Base(path), profile: profile})
+		}
+	}
+	for _, profile := range profiles.Profiles {
+		if profile.FilePattern != "" {
+			sources = append(sources, profileSource{bankSource: profile.FilePattern, profile: profile})
+		}
+	}
+	return sources
+}
+
+// feeSchedule derives the usecase fee rules from the bank profiles, one per profile source
+// even without a fee, so that a statement takes the fee of the profile it was parsed with.
+func feeSchedule(profiles *gateway.ProfileSet, assignments map[string]string) []usecase.FeeRule {
+	var rules []usecase.FeeRule
+	for _, ps := range profileSources(profiles, assignments) {
+		rules = append(rules, usecase.FeeRule{BankSource: ps.bankSource, Fixed: ps.profile.FeeFixed, Percent: ps.profile.FeePercent})
+	}
+	return rules
+}
+
+// hasFees reports whether any of the fee rules deducts a fee.
+func hasFees(rules []usecase.FeeRule) bool {
+	for _, rule := range rules {
+		if !rule.Fixed.IsZero() || !rule.Percent.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceRules derives the usecase reference rules from the bank profiles, in the same
+// precedence as feeSchedule.
+func referenceRules(profiles *gateway.ProfileSet, assignments map[string]string) ([]usecase.ReferenceRule, error) {
+	var rules []usecase.ReferenceRule
+	for _, ps := range profileSources(profiles, assignments) {
+		rule, err := usecase.NewReferenceRule(ps.bankSource, ps.profile.ReferencePatterns...)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", ps.profile.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
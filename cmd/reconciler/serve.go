@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"mini-reconciliation/internal/gateway"
+	"mini-reconciliation/internal/server"
+	"mini-reconciliation/internal/usecase"
+)
+
+// runServe serves reconciliations over HTTP until interrupted, then waits for the requests
+// and background runs in flight to finish. Errors are returned rather than exiting, so that
+// the run database is closed on the way out.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "serve", "Serve reconciliations of uploaded files over HTTP.")
+	addr := fs.String("addr", ":8080", "Address to listen on")
+	profilesFile := fs.String("profiles", "", "Path to a YAML or JSON file of bank statement profiles, matched by uploaded file name")
+	var matchingFlags matchingFlags
+	matchingFlags.register(fs)
+	maxUploadMB := fs.Int64("max-upload-mb", server.DefaultMaxUploadBytes>>20, "Largest request body accepted, in MiB")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "How long to wait for requests and runs in flight when shutting down")
+	runsDB := fs.String("runs-db", "", "Path to a SQLite database of background runs; enables the /runs endpoints")
+	workers := fs.Int("workers", 2, "With -runs-db, the number of runs executed at once")
+	queueSize := fs.Int("queue", 100, "With -runs-db, the number of submitted runs that may wait for a worker")
+	fs.Parse(args)
+
+	var repoOpts []gateway.Option
+	var profiles *gateway.ProfileSet
+	if *profilesFile != "" {
+		var err error
+		if profiles, err = gateway.LoadProfiles(*profilesFile); err != nil {
+			return fmt.Errorf("loading bank profiles: %w", err)
+		}
+		repoOpts = append(repoOpts, gateway.WithProfiles(profiles))
+	}
+	ucOpts, err := matchingFlags.options(profiles, nil)
+	if err != nil {
+		return fmt.Errorf("configuring matching: %w", err)
+	}
+
+	serverOpts := []server.Option{
+		server.WithRepositoryOptions(repoOpts...),
+		server.WithUseCaseOptions(ucOpts...),
+		server.WithMaxUploadBytes(*maxUploadMB << 20),
+	}
+	if *runsDB != "" {
+		store, err := gateway.OpenSQLiteRunStore(*runsDB)
+		if err != nil {
+			return fmt.Errorf("opening run database: %w", err)
+		}
+		defer store.Close()
+		runs := usecase.NewRunUseCase(store)
+		if n, err := runs.FailInterrupted(context.Background()); err != nil {
+			return fmt.Errorf("recovering runs: %w", err)
+		} else if n > 0 {
+			log.Printf("Marked %d runs interrupted by the last shutdown as failed", n)
+		}
+		serverOpts = append(serverOpts, server.WithRuns(runs, *workers, *queueSize))
+	}
+	app := server.New(serverOpts...)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           app.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("serving: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for requests in flight", *shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Shutdown failed: %v", err)
+	}
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Cancelled the runs still in progress: %v", err)
+	}
+	return nil
+}
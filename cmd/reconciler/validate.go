@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/gateway"
+)
+
+// validationReport is the output of the validate command.
+type validationReport struct {
+	Valid bool                     `json:"valid"`
+	Files []gateway.FileValidation `json:"files"`
+}
+
+// runValidate parses every input file and reports the rows that cannot be read, without
+// reconciling. It exits with status 1 when any file has errors.
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "validate", "Parse the input files and report schema and row errors, without matching.")
+	var inputFlags inputFlags
+	inputFlags.register(fs, "")
+	fxRatesFile := fs.String("fx-rates", "", "Path to a CSV of daily FX rates (date,from,to,rate)")
+	overridesFile := fs.String("overrides", "", "Path to a CSV or JSON file of manual match and ignore overrides")
+	balancesFile := fs.String("balances", "", "Path to a CSV of statement opening/closing balances")
+	fs.Parse(args)
+
+	if inputFlags.system == "" && inputFlags.bank == "" {
+		fmt.Println("Error: -system or -bank is required.")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	in, err := inputFlags.load()
+	if err != nil {
+		log.Fatalf("Error loading bank profiles: %v", err)
+	}
+
+	ctx := context.Background()
+	report := validationReport{Valid: true}
+	if in.system != nil {
+		report.Files = append(report.Files, in.repo.ValidateSystemFile(ctx, *in.system))
+	}
+	for _, source := range in.banks {
+		report.Files = append(report.Files, in.repo.ValidateBankFile(ctx, source))
+	}
+	if *fxRatesFile != "" {
+		_, err := gateway.LoadFXRates(*fxRatesFile)
+		report.Files = append(report.Files, loadValidation(*fxRatesFile, err))
+	}
+	if *overridesFile != "" {
+		_, err := gateway.LoadOverrides(*overridesFile)
+		report.Files = append(report.Files, loadValidation(*overridesFile, err))
+	}
+	if *balancesFile != "" {
+		_, err := gateway.LoadBalances(*balancesFile)
+		report.Files = append(report.Files, loadValidation(*balancesFile, err))
+	}
+	for _, file := range report.Files {
+		report.Valid = report.Valid && file.Valid()
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to generate JSON report: %v", err)
+	}
+	fmt.Println(string(output))
+	if !report.Valid {
+		os.Exit(1)
+	}
+}
+
+// loadValidation reports the outcome of loading a supporting file, which stops at its
+// first error.
+func loadValidation(path string, err error) gateway.FileValidation {
+	v := gateway.FileValidation{File: path, Errors: make([]domain.IngestionError, 0)}
+	if err != nil {
+		v.Errors = append(v.Errors, domain.IngestionError{File: path, Reason: err.Error()})
+	}
+	return v
+}
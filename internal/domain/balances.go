@@ -0,0 +1,85 @@
+package domain
+
+import "encoding/json"
+
+// StatementBalance holds the opening and closing balance of one bank statement, in the
+// statement's currency. A balance missing from the statement is nil, and so is a currency
+// not known from the balances alone. In reports its amounts are scaled by the MarshalJSON
+// of the BankBalance embedding it; a method of its own would be promoted over that one.
+type StatementBalance struct {
+	BankSource string   `json:"bank_source"`
+	Currency   Currency `json:"currency,omitempty"`
+	Opening    *Decimal `json:"opening_balance,omitempty"`
+	Closing    *Decimal `json:"closing_balance,omitempty"`
+}
+
+// BankBalance checks that a statement's opening balance plus its movements in the period
+// equals its closing balance. Balanced is nil when either balance is missing, since the
+// statement can then be neither confirmed nor disputed.
+type BankBalance struct {
+	StatementBalance
+	Movements       Decimal       `json:"movements"`                  // sum of signed statement amounts in the period
+	ExpectedClosing *Decimal      `json:"expected_closing,omitempty"` // opening balance plus movements
+	Difference      *Decimal      `json:"difference,omitempty"`       // closing minus expected closing balance
+	Balanced        *bool         `json:"balanced"`
+	ClosingFX       *FXConversion `json:"closing_fx,omitempty"` // closing balance in the reporting currency, for the ledger
+}
+
+// MarshalJSON encodes the balance with its amounts, those of the embedded statement
+// balance included, at the scale of the statement's currency.
+func (b BankBalance) MarshalJSON() ([]byte, error) {
+	type fields BankBalance
+	return json.Marshal(struct {
+		fields
+		Opening         json.RawMessage `json:"opening_balance,omitempty"`
+		Closing         json.RawMessage `json:"closing_balance,omitempty"`
+		Movements       json.RawMessage `json:"movements"`
+		ExpectedClosing json.RawMessage `json:"expected_closing,omitempty"`
+		Difference      json.RawMessage `json:"difference,omitempty"`
+	}{
+		fields(b),
+		b.Currency.optionalAmountJSON(b.Opening),
+		b.Currency.optionalAmountJSON(b.Closing),
+		b.Currency.amountJSON(b.Movements),
+		b.Currency.optionalAmountJSON(b.ExpectedClosing),
+		b.Currency.optionalAmountJSON(b.Difference),
+	})
+}
+
+// LedgerBalance compares the system's expected ledger balance with the bank balances, in
+// the reporting currency. Balanced is nil unless every bank closing balance is known.
+type LedgerBalance struct {
+	Currency         Currency `json:"currency"`
+	Opening          Decimal  `json:"opening_balance"`
+	Movements        Decimal  `json:"movements"`                    // credits minus debits of system transactions in the period
+	ExpectedClosing  Decimal  `json:"expected_closing"`             // opening balance plus movements
+	BankClosingTotal *Decimal `json:"bank_closing_total,omitempty"` // sum of the bank closing balances, when all are known
+	Difference       *Decimal `json:"difference,omitempty"`         // bank closing total minus expected ledger closing balance
+	Balanced         *bool    `json:"balanced"`
+}
+
+// MarshalJSON encodes the balance with its amounts at the scale of the reporting currency.
+func (l LedgerBalance) MarshalJSON() ([]byte, error) {
+	type fields LedgerBalance
+	return json.Marshal(struct {
+		fields
+		Opening          json.RawMessage `json:"opening_balance"`
+		Movements        json.RawMessage `json:"movements"`
+		ExpectedClosing  json.RawMessage `json:"expected_closing"`
+		BankClosingTotal json.RawMessage `json:"bank_closing_total,omitempty"`
+		Difference       json.RawMessage `json:"difference,omitempty"`
+	}{
+		fields(l),
+		l.Currency.amountJSON(l.Opening),
+		l.Currency.amountJSON(l.Movements),
+		l.Currency.amountJSON(l.ExpectedClosing),
+		l.Currency.optionalAmountJSON(l.BankClosingTotal),
+		l.Currency.optionalAmountJSON(l.Difference),
+	})
+}
+
+// Balances is the balance-level reconciliation of the period.
+type Balances struct {
+	Banks  []BankBalance  `json:"banks"`
+	Ledger *LedgerBalance `json:"ledger,omitempty"`
+}
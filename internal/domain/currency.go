@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code, e.g. "IDR" or "USD".
+type Currency string
+
+// DefaultCurrency is assumed for transactions whose source does not state a currency.
+const DefaultCurrency Currency = "IDR"
+
+// currencyScales lists currencies whose minor unit is not 2 decimal places.
+var currencyScales = map[Currency]int32{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// ParseCurrency normalizes a currency code, falling back to DefaultCurrency when empty.
+func ParseCurrency(code string) Currency {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency
+	}
+	return Currency(code)
+}
+
+// Scale returns the number of decimal places of the currency's minor unit.
+func (c Currency) Scale() int32 {
+	if scale, ok := currencyScales[c]; ok {
+		return scale
+	}
+	return 2
+}
+
+// Round rounds an amount to the currency's minor unit.
+func (c Currency) Round(amount Decimal) Decimal {
+	return amount.Round(c.Scale())
+}
+
+// ParseAmount parses an amount of the currency, rejecting more fractional digits than its
+// minor unit has.
+func (c Currency) ParseAmount(s string) (Decimal, error) {
+	amount, err := ParseDecimal(s)
+	if err != nil {
+		return Decimal{}, err
+	}
+	if amount.Scale() > c.Scale() {
+		return Decimal{}, fmt.Errorf("more than %d decimal places for %s", c.Scale(), c)
+	}
+	return amount, nil
+}
+
+// amountJSON encodes an amount as a JSON number with exactly the digits of the currency's
+// minor unit, e.g. 1000.00 for IDR.
+func (c Currency) amountJSON(amount Decimal) json.RawMessage {
+	return json.RawMessage(amount.StringFixed(ParseCurrency(string(c)).Scale()))
+}
+
+// optionalAmountJSON is amountJSON for an amount that may be missing, which encodes as
+// nothing so that an omitempty field is left out.
+func (c Currency) optionalAmountJSON(amount *Decimal) json.RawMessage {
+	if amount == nil {
+		return nil
+	}
+	return c.amountJSON(*amount)
+}
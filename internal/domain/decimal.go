@@ -0,0 +1,369 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale bounds the number of fractional digits a Decimal may carry.
+const maxDecimalScale = 18
+
+// Decimal is an exact fixed-point number equal to coef × 10^-scale.
+// Values are kept normalized (no trailing fractional zeros), so two Decimals holding the
+// same number are always == and compare equal with reflect.DeepEqual. The zero value is 0.
+// Coefficients that do not fit an int64 are kept in wide, so arithmetic never overflows.
+type Decimal struct {
+	coef  int64
+	scale int32
+	wide  string // the coefficient in base 10 when it does not fit coef, which is then 0
+}
+
+// NewDecimal returns coef × 10^-scale.
+func NewDecimal(coef int64, scale int32) Decimal {
+	if scale < 0 || scale > maxDecimalScale {
+		panic(fmt.Sprintf("decimal scale %d out of range", scale))
+	}
+	return Decimal{coef: coef, scale: scale}.normalize()
+}
+
+// NewDecimalFromInt returns the integer value i.
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{coef: i}
+}
+
+// ParseDecimal parses a plain decimal string such as "-1234.50".
+// Exponents, thousands separators and currency symbols are not accepted.
+func ParseDecimal(s string) (Decimal, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	negative := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", raw)
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return Decimal{}, fmt.Errorf("invalid decimal %q", raw)
+			}
+		}
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: more than %d fractional digits", raw, maxDecimalScale)
+	}
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if digits == "" {
+		return Decimal{}, nil
+	}
+	if negative {
+		digits = "-" + digits
+	}
+	coef, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		wide, _ := new(big.Int).SetString(digits, 10)
+		return fromBig(wide, int32(len(fracPart))), nil
+	}
+	return Decimal{coef: coef, scale: int32(len(fracPart))}.normalize(), nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error. It is intended for constants and tests.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	if a, b, scale, ok := alignInt64(d, o); ok {
+		if sum := a + b; (b >= 0) == (sum >= a) {
+			return Decimal{coef: sum, scale: scale}.normalize()
+		}
+	}
+	a, b, scale := alignBig(d, o)
+	return fromBig(a.Add(a, b), scale)
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul returns the exact product d × o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	product := new(big.Int).Mul(d.coefficient(), o.coefficient())
+	return fromBig(product, d.scale+o.scale)
+}
+
+// Div returns d ÷ o rounded half away from zero to the given number of fractional digits.
+// It panics if o is zero.
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	if o.IsZero() {
+		panic("decimal division by zero")
+	}
+	// d/o = (d.coef / o.coef) × 10^(o.scale - d.scale); scale the numerator so the
+	// integer quotient carries `places` fractional digits.
+	num := d.coefficient()
+	den := o.coefficient()
+	shift := int64(places) + int64(o.scale) - int64(d.scale)
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return fromBig(roundQuotient(num, den), places)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	if d.wide != "" || d.coef == math.MinInt64 {
+		return fromBig(new(big.Int).Neg(d.coefficient()), d.scale)
+	}
+	return Decimal{coef: -d.coef, scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	switch {
+	case d.wide != "" && d.wide[0] == '-':
+		return -1
+	case d.wide != "":
+		return 1
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.coef == 0 && d.wide == ""
+}
+
+// Cmp returns -1, 0 or +1 depending on whether d is less than, equal to, or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	if a, b, _, ok := alignInt64(d, o); ok {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	return d.big().Cmp(o.big())
+}
+
+// Equal reports whether d and o represent the same number.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Scale returns the number of fractional digits in d.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Round rounds d half away from zero to the given number of fractional digits.
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.scale <= places {
+		return d
+	}
+	return fromBig(roundQuotient(d.coefficient(), pow10(int64(d.scale-places))), places)
+}
+
+// Float64 returns the nearest float64 to d. It is meant for presentation and statistics, never for matching.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the plain decimal representation of d, e.g. "-1234.5".
+func (d Decimal) String() string {
+	return d.format(d.scale)
+}
+
+// StringFixed returns d rounded to and padded with exactly the given number of fractional digits.
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+	return d.Round(places).format(places)
+}
+
+// MarshalJSON encodes d as a JSON number without loss of precision.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding a decimal.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// UnmarshalText parses a decimal from text, e.g. a YAML scalar.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) format(places int32) string {
+	neg := d.Sign() < 0
+	digits := new(big.Int).Abs(d.coefficient()).String()
+	if pad := int(places - d.scale); pad > 0 {
+		digits += strings.Repeat("0", pad)
+	}
+	if places > 0 {
+		if len(digits) <= int(places) {
+			digits = strings.Repeat("0", int(places)-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-int(places)] + "." + digits[len(digits)-int(places):]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// normalize drops the trailing fractional zeros of a Decimal held in coef.
+func (d Decimal) normalize() Decimal {
+	if d.coef == 0 {
+		return Decimal{}
+	}
+	for d.scale > 0 && d.coef%10 == 0 {
+		d.coef /= 10
+		d.scale--
+	}
+	return d
+}
+
+func (d Decimal) big() *big.Rat {
+	return new(big.Rat).SetFrac(d.coefficient(), pow10(int64(d.scale)))
+}
+
+// coefficient returns the coefficient of d as a new big.Int.
+func (d Decimal) coefficient() *big.Int {
+	if d.wide != "" {
+		coef, _ := new(big.Int).SetString(d.wide, 10)
+		return coef
+	}
+	return big.NewInt(d.coef)
+}
+
+// alignInt64 returns the coefficients of a and b expressed at a common scale, unless that
+// does not fit an int64.
+func alignInt64(a, b Decimal) (int64, int64, int32, bool) {
+	if a.wide != "" || b.wide != "" {
+		return 0, 0, 0, false
+	}
+	switch {
+	case a.scale == b.scale:
+		return a.coef, b.coef, a.scale, true
+	case a.scale < b.scale:
+		coef, ok := rescaleInt64(a.coef, b.scale-a.scale)
+		return coef, b.coef, b.scale, ok
+	default:
+		coef, ok := rescaleInt64(b.coef, a.scale-b.scale)
+		return a.coef, coef, a.scale, ok
+	}
+}
+
+func rescaleInt64(coef int64, by int32) (int64, bool) {
+	factor := int64(1)
+	for ; by > 0; by-- {
+		factor *= 10
+	}
+	scaled := coef * factor
+	return scaled, scaled/factor == coef
+}
+
+// alignBig returns the coefficients of a and b expressed at a common scale.
+func alignBig(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := max(a.scale, b.scale)
+	x := new(big.Int).Mul(a.coefficient(), pow10(int64(scale-a.scale)))
+	y := new(big.Int).Mul(b.coefficient(), pow10(int64(scale-b.scale)))
+	return x, y, scale
+}
+
+// fromBig builds a normalized Decimal from an arbitrary-size coefficient, rounding away
+// digits beyond maxDecimalScale and dropping trailing zeros before checking that it fits.
+func fromBig(coef *big.Int, scale int32) Decimal {
+	if scale > maxDecimalScale {
+		coef = roundQuotient(coef, pow10(int64(scale-maxDecimalScale)))
+		scale = maxDecimalScale
+	}
+	ten := big.NewInt(10)
+	for scale > 0 && coef.Sign() != 0 {
+		q, r := new(big.Int).QuoRem(coef, ten, new(big.Int))
+		if r.Sign() != 0 {
+			break
+		}
+		coef, scale = q, scale-1
+	}
+	if !coef.IsInt64() {
+		return Decimal{scale: scale, wide: coef.String()}
+	}
+	return Decimal{coef: coef.Int64(), scale: scale}.normalize()
+}
+
+// roundQuotient returns num/den rounded half away from zero.
+func roundQuotient(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	twice := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2))
+	if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
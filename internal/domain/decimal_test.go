@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "150.00", want: "150"},
+		{input: "-75.25", want: "-75.25"},
+		{input: "+0.10", want: "0.1"},
+		{input: ".5", want: "0.5"},
+		{input: "  1200000000000.01 ", want: "1200000000000.01"},
+		{input: "0.000", want: "0"},
+		{input: "", wantErr: true},
+		{input: "-", wantErr: true},
+		{input: "1,000.00", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "99999999999999999999", want: "99999999999999999999"},
+		{input: "0.0000000000000000001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDecimal(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	// Normalized values compare equal regardless of how they were written.
+	assert.Equal(t, MustParseDecimal("100.50"), MustParseDecimal("100.5"))
+
+	// Summing ten cents ten times is exact, unlike float64.
+	total := Decimal{}
+	for i := 0; i < 10; i++ {
+		total = total.Add(MustParseDecimal("0.10"))
+	}
+	assert.Equal(t, NewDecimalFromInt(1), total)
+
+	assert.Equal(t, "0.55", MustParseDecimal("100.00").Sub(MustParseDecimal("99.95")).Add(MustParseDecimal("0.50")).String())
+	assert.Equal(t, "-1", MustParseDecimal("3").Sub(MustParseDecimal("4")).String())
+	assert.Equal(t, "15.5", MustParseDecimal("1.55").Mul(MustParseDecimal("10")).String())
+	assert.Equal(t, "0.3333", MustParseDecimal("1").Div(MustParseDecimal("3"), 4).String())
+	assert.Equal(t, "-0.6667", MustParseDecimal("-2").Div(MustParseDecimal("3"), 4).String())
+	assert.Equal(t, 1, MustParseDecimal("0.3").Cmp(MustParseDecimal("0.29")))
+	assert.Equal(t, -1, MustParseDecimal("-5").Sign())
+	assert.True(t, MustParseDecimal("0.00").IsZero())
+
+	// Values past an int64 coefficient are carried exactly instead of overflowing.
+	assert.Equal(t, "9223372036854775808", NewDecimal(9223372036854775807, 0).Add(NewDecimalFromInt(1)).String())
+	assert.Equal(t, "10.000000000000000001", MustParseDecimal("0.000000000000000001").Add(NewDecimalFromInt(10)).String())
+	assert.Equal(t, "1000000000.0000000001", MustParseDecimal("1000000000").Add(MustParseDecimal("0.0000000001")).String())
+	assert.Equal(t, "60788412.000030394206", MustParseDecimal("1000000000000.50").Mul(MustParseDecimal("0.000060788412")).String())
+	wide := MustParseDecimal("-99999999999999999999.5")
+	assert.Equal(t, MustParseDecimal("-99999999999999999999.50"), wide)
+	assert.Equal(t, -1, wide.Sign())
+	assert.Equal(t, "99999999999999999999.5", wide.Abs().String())
+	assert.Equal(t, "-1", wide.Add(MustParseDecimal("99999999999999999998.5")).String())
+	assert.Equal(t, 1, NewDecimalFromInt(0).Cmp(wide))
+	assert.Equal(t, "-100000000000000000000", wide.Round(0).String())
+	assert.Equal(t, "-9223372036854775808", NewDecimal(-9223372036854775807, 0).Sub(NewDecimalFromInt(1)).String())
+	assert.Equal(t, "9223372036854775808", NewDecimal(-9223372036854775807, 0).Sub(NewDecimalFromInt(1)).Neg().String())
+}
+
+func TestDecimal_Round(t *testing.T) {
+	assert.Equal(t, "2.35", MustParseDecimal("2.345").Round(2).String())
+	assert.Equal(t, "-2.35", MustParseDecimal("-2.345").Round(2).String())
+	assert.Equal(t, "2.34", MustParseDecimal("2.3449").Round(2).String())
+	assert.Equal(t, "13", MustParseDecimal("12.5").Round(0).String())
+	assert.Equal(t, "150.00", MustParseDecimal("150").StringFixed(2))
+	assert.Equal(t, "-0.050", MustParseDecimal("-0.05").StringFixed(3))
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount Decimal `json:"amount"`
+	}{Amount: MustParseDecimal("1234567890123.45")})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":1234567890123.45}`, string(data))
+
+	var decoded struct {
+		Number Decimal `json:"number"`
+		Text   Decimal `json:"text"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"number": 0.1, "text": "-20.00"}`), &decoded))
+	assert.Equal(t, MustParseDecimal("0.1"), decoded.Number)
+	assert.Equal(t, MustParseDecimal("-20"), decoded.Text)
+}
+
+func TestCurrency_Scale(t *testing.T) {
+	assert.Equal(t, int32(2), ParseCurrency("idr").Scale())
+	assert.Equal(t, int32(0), Currency("JPY").Scale())
+	assert.Equal(t, int32(3), Currency("KWD").Scale())
+	assert.Equal(t, DefaultCurrency, ParseCurrency(" "))
+	assert.Equal(t, "1.235", Currency("KWD").Round(MustParseDecimal("1.2345")).String())
+}
+
+func TestCurrency_ParseAmount(t *testing.T) {
+	amount, err := Currency("IDR").ParseAmount("1000.50")
+	assert.NoError(t, err)
+	assert.Equal(t, MustParseDecimal("1000.5"), amount)
+	_, err = Currency("IDR").ParseAmount("0.0000000001")
+	assert.EqualError(t, err, "more than 2 decimal places for IDR")
+	_, err = Currency("JPY").ParseAmount("100.5")
+	assert.EqualError(t, err, "more than 0 decimal places for JPY")
+}
+
+func TestTransaction_JSON(t *testing.T) {
+	data, err := json.Marshal(SystemTransaction{TrxID: "SYS001", Amount: MustParseDecimal("1000"), Currency: "IDR", Type: TransactionTypeDebit})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"amount":1000.00`)
+	assert.JSONEq(t, `{"trxID":"SYS001","amount":1000.00,"currency":"IDR","type":"DEBIT","transactionTime":"0001-01-01T00:00:00Z"}`, string(data))
+
+	data, err = json.Marshal(BankTransaction{UniqueIdentifier: "B1", Amount: MustParseDecimal("-1.5"), Currency: "KWD", Description: "Fee", BankSource: "bank.csv"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"amount":-1.500`)
+	assert.JSONEq(t, `{"unique_identifier":"B1","amount":-1.500,"currency":"KWD","date":"0001-01-01T00:00:00Z","description":"Fee","bank_source":"bank.csv"}`, string(data))
+
+	var decoded SystemTransaction
+	assert.NoError(t, json.Unmarshal([]byte(`{"trxID":"SYS001","amount":1000.00,"currency":"IDR"}`), &decoded))
+	assert.Equal(t, MustParseDecimal("1000"), decoded.Amount)
+}
+
+func TestBalances_JSON(t *testing.T) {
+	opening, closing, expected, diff := MustParseDecimal("1000"), MustParseDecimal("825.5"), MustParseDecimal("825.5"), MustParseDecimal("0")
+	balanced := true
+	data, err := json.Marshal(BankBalance{
+		StatementBalance: StatementBalance{BankSource: "bank.csv", Currency: "IDR", Opening: &opening, Closing: &closing},
+		Movements:        MustParseDecimal("-174.5"),
+		ExpectedClosing:  &expected,
+		Difference:       &diff,
+		Balanced:         &balanced,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"bank_source":"bank.csv","currency":"IDR","opening_balance":1000.00,"closing_balance":825.50,"movements":-174.50,"expected_closing":825.50,"difference":0.00,"balanced":true}`, string(data))
+	assert.Contains(t, string(data), `"movements":-174.50`)
+
+	data, err = json.Marshal(BankBalance{StatementBalance: StatementBalance{BankSource: "bank.csv", Currency: "JPY"}, Movements: MustParseDecimal("780")})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"bank_source":"bank.csv","currency":"JPY","movements":780,"balanced":null}`, string(data))
+
+	data, err = json.Marshal(LedgerBalance{Currency: "IDR", Opening: MustParseDecimal("1000"), Movements: MustParseDecimal("780"), ExpectedClosing: MustParseDecimal("1780")})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"opening_balance":1000.00`)
+	assert.JSONEq(t, `{"currency":"IDR","opening_balance":1000.00,"movements":780.00,"expected_closing":1780.00,"balanced":null}`, string(data))
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FXRate is the number of units of To that one unit of From buys on Date.
+type FXRate struct {
+	From Currency  `json:"from"`
+	To   Currency  `json:"to"`
+	Date time.Time `json:"date"`
+	Rate Decimal   `json:"rate"`
+}
+
+// FXConversion records how an amount was converted into the reporting currency.
+type FXConversion struct {
+	From            Currency `json:"from"`
+	To              Currency `json:"to"`
+	Rate            Decimal  `json:"rate"`
+	RateDate        string   `json:"rate_date"`
+	OriginalAmount  Decimal  `json:"original_amount"`
+	ConvertedAmount Decimal  `json:"converted_amount"`
+}
+
+// MarshalJSON encodes the conversion with each amount at the scale of its currency.
+func (c FXConversion) MarshalJSON() ([]byte, error) {
+	type fields FXConversion
+	return json.Marshal(struct {
+		fields
+		OriginalAmount  json.RawMessage `json:"original_amount"`
+		ConvertedAmount json.RawMessage `json:"converted_amount"`
+	}{fields(c), c.From.amountJSON(c.OriginalAmount), c.To.amountJSON(c.ConvertedAmount)})
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInput is matched by errors.Is for errors caused by the content of the input
+// files, such as a row or header that cannot be read, as opposed to failures to read them.
+var ErrInvalidInput = errors.New("invalid input")
+
+// IngestionError describes an input row (or header) that could not be read.
+type IngestionError struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"` // 1-based line in the file (the header is line 1), or 0 for the file as a whole
+	Column string `json:"column,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e IngestionError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("%s:%d: could not parse %s '%s': %s", e.File, e.Line, e.Column, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// Is reports an IngestionError as invalid input.
+func (e IngestionError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// InvalidInput marks err as caused by the content of the input files, keeping its message.
+func InvalidInput(err error) error {
+	return invalidInputError{err}
+}
+
+type invalidInputError struct {
+	err error
+}
+
+func (e invalidInputError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidInputError) Unwrap() error {
+	return e.err
+}
+
+func (e invalidInputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// IngestionErrors lists the input rows skipped because they could not be read.
+type IngestionErrors struct {
+	Count   int              `json:"count"`
+	Details []IngestionError `json:"details"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+// OpenItem is a transaction left unmatched at the end of a reconciliation period and
+// carried forward to be matched in a later one. Exactly one of System and Bank is set.
+type OpenItem struct {
+	System     *SystemTransaction `json:"system_transaction,omitempty"`
+	Bank       *BankTransaction   `json:"bank_transaction,omitempty"`
+	References []string           `json:"references,omitempty"` // the bank transaction's ReferenceFields, which it does not marshal
+	FirstSeen  string             `json:"first_seen"`           // end of the period the item was first left open, YYYY-MM-DD
+}
+
+// BankTransaction returns the carried bank transaction with its reference fields.
+func (i OpenItem) BankTransaction() BankTransaction {
+	tx := *i.Bank
+	tx.ReferenceFields = i.References
+	return tx
+}
+
+// OpenItems is the set of open items as of the end of a reconciliation period.
+type OpenItems struct {
+	AsOf  string     `json:"as_of"` // end of the period, YYYY-MM-DD
+	Items []OpenItem `json:"items"`
+}
+
+// OpenItemAge describes one transaction still open at the end of the period.
+type OpenItemAge struct {
+	SystemTrxID          string `json:"system_trx_id,omitempty"`
+	BankUniqueIdentifier string `json:"bank_unique_identifier,omitempty"`
+	BankSource           string `json:"bank_source,omitempty"`
+	Date                 string `json:"date"`
+	FirstSeen            string `json:"first_seen"`
+	AgeDays              int    `json:"age_days"` // period end minus transaction date, in calendar days
+	CarriedForward       bool   `json:"carried_forward"`
+}
+
+// OpenItemsReport summarizes the carry-forward of open items across periods.
+type OpenItemsReport struct {
+	CarriedIn int           `json:"carried_in"` // open items brought in from earlier periods
+	Cleared   int           `json:"cleared"`    // carried-in items matched in this period
+	Open      []OpenItemAge `json:"open"`       // every item left open at the end of this period
+}
@@ -0,0 +1,34 @@
+package domain
+
+// OverrideAction is what a manual override does.
+type OverrideAction string
+
+const (
+	// OverrideMatch pairs a system transaction with a bank transaction.
+	OverrideMatch OverrideAction = "match"
+	// OverrideIgnore excludes a system or bank transaction from reconciliation.
+	OverrideIgnore OverrideAction = "ignore"
+)
+
+// Override is a manual decision taken by operations, e.g. "bank BANK_B_2 is SYS017".
+// BankSource optionally narrows a bank identifier down to one statement file.
+type Override struct {
+	Action               OverrideAction `json:"action"`
+	SystemTrxID          string         `json:"system_trx_id,omitempty"`
+	BankUniqueIdentifier string         `json:"bank_unique_identifier,omitempty"`
+	BankSource           string         `json:"bank_source,omitempty"`
+	Note                 string         `json:"note,omitempty"`
+}
+
+// OverrideError reports an override that could not be applied.
+type OverrideError struct {
+	Override Override `json:"override"`
+	Error    string   `json:"error"`
+}
+
+// IgnoredTransactions lists the transactions excluded from reconciliation by overrides.
+type IgnoredTransactions struct {
+	Count              int                 `json:"count"`
+	SystemTransactions []SystemTransaction `json:"system_transactions"`
+	BankTransactions   []BankTransaction   `json:"bank_transactions"`
+}
@@ -1,18 +1,85 @@
 package domain
 
+import "encoding/json"
+
+// DiscrepancyReason classifies why the amounts of a matched pair differ.
+type DiscrepancyReason string
+
+const (
+	DiscrepancyAmountMismatch  DiscrepancyReason = "amount_mismatch"
+	DiscrepancyFeeDeducted     DiscrepancyReason = "fee_deducted"
+	DiscrepancyWithinTolerance DiscrepancyReason = "within_tolerance"
+)
+
 // DiscrepancyDetail provides details on a single discrepant transaction.
+// Amounts in different currencies are compared in the reporting currency; the conversions
+// applied to each side are recorded alongside.
 type DiscrepancyDetail struct {
 	SystemTransaction SystemTransaction `json:"system_transaction"`
 	BankTransaction   BankTransaction   `json:"bank_transaction"`
+	Reason            DiscrepancyReason `json:"reason"`
+	Difference        Decimal           `json:"difference"` // system minus bank amount, in the reporting currency
+	DayOffset         int               `json:"day_offset"` // bank booking date minus system date, in calendar days
+	SystemFX          *FXConversion     `json:"system_fx,omitempty"`
+	BankFX            *FXConversion     `json:"bank_fx,omitempty"`
+}
+
+// MarshalJSON encodes the detail with its difference at the scale of the reporting
+// currency, which is what the system amount was converted to.
+func (d DiscrepancyDetail) MarshalJSON() ([]byte, error) {
+	currency := d.SystemTransaction.Currency
+	if d.SystemFX != nil {
+		currency = d.SystemFX.To
+	}
+	type fields DiscrepancyDetail
+	return json.Marshal(struct {
+		fields
+		Difference json.RawMessage `json:"difference"`
+	}{fields(d), currency.amountJSON(d.Difference)})
 }
 
 // DiscrepantTransactions holds summary information about all discrepancies found.
+// TotalDiscrepancyValue is expressed in the reporting currency.
 type DiscrepantTransactions struct {
 	Count                 int                 `json:"count"`
-	TotalDiscrepancyValue float64             `json:"total_discrepancy_value"`
+	Currency              Currency            `json:"currency"`
+	TotalDiscrepancyValue Decimal             `json:"total_discrepancy_value"`
 	Details               []DiscrepancyDetail `json:"details"`
 }
 
+// MarshalJSON encodes the total at the scale of the reporting currency.
+func (d DiscrepantTransactions) MarshalJSON() ([]byte, error) {
+	type fields DiscrepantTransactions
+	return json.Marshal(struct {
+		fields
+		TotalDiscrepancyValue json.RawMessage `json:"total_discrepancy_value"`
+	}{fields(d), d.Currency.amountJSON(d.TotalDiscrepancyValue)})
+}
+
+// GroupedMatch is a set of system transactions settled as a set of bank transactions with
+// the same total, e.g. many payments paid out by a gateway as one bank credit.
+type GroupedMatch struct {
+	SystemTransactions []SystemTransaction `json:"system_transactions"`
+	BankTransactions   []BankTransaction   `json:"bank_transactions"`
+	Total              Decimal             `json:"total"`    // in the reporting currency
+	Currency           Currency            `json:"currency"` // the reporting currency
+}
+
+// MarshalJSON encodes the total at the scale of the reporting currency.
+func (g GroupedMatch) MarshalJSON() ([]byte, error) {
+	type fields GroupedMatch
+	return json.Marshal(struct {
+		fields
+		Total json.RawMessage `json:"total"`
+	}{fields(g), g.Currency.amountJSON(g.Total)})
+}
+
+// GroupedMatches lists all one-to-many and many-to-one matches found.
+type GroupedMatches struct {
+	Count   int            `json:"count"`
+	Details []GroupedMatch `json:"details"`
+}
+
 // UnmatchedTransactions lists all transactions that could not be matched.
 type UnmatchedTransactions struct {
 	Count                 int                          `json:"count"`
@@ -20,18 +87,61 @@ type UnmatchedTransactions struct {
 	BankMissingFromSystem map[string][]BankTransaction `json:"bank_missing_from_system"`
 }
 
+// MatchedPair identifies a matched system and bank transaction and how far apart they were booked.
+// Ambiguous pairs were picked out of several equally valid candidates and deserve a manual check;
+// manual pairs come from an override file.
+type MatchedPair struct {
+	SystemTrxID          string `json:"system_trx_id"`
+	BankUniqueIdentifier string `json:"bank_unique_identifier"`
+	BankSource           string `json:"bank_source"`
+	SystemDate           string `json:"system_date"`
+	BankDate             string `json:"bank_date"`
+	DayOffset            int    `json:"day_offset"` // bank booking date minus system date, in calendar days
+	Ambiguous            bool   `json:"ambiguous,omitempty"`
+	Manual               bool   `json:"manual,omitempty"`
+}
+
+// MatchExplanation records which matching pass paired a system and a bank transaction,
+// what it matched them on and how confident the match is, from 0 to 1.
+type MatchExplanation struct {
+	MatchedPair
+	Pass       string  `json:"pass"`
+	Key        string  `json:"key"`
+	Confidence float64 `json:"confidence"`
+}
+
+// MatchedTransactions lists every matched pair with its explanation. Transactions matched
+// as a group appear once per system and bank transaction pair.
+type MatchedTransactions struct {
+	Count   int                `json:"count"`
+	Details []MatchExplanation `json:"details"`
+}
+
 // Summary provides high-level statistics of the reconciliation process.
 type Summary struct {
-	TimeframeStart                   string `json:"timeframe_start"`
-	TimeframeEnd                     string `json:"timeframe_end"`
-	TotalSystemTransactionsProcessed int    `json:"total_system_transactions_processed"`
-	TotalBankTransactionsProcessed   int    `json:"total_bank_transactions_processed"`
-	MatchedTransactions              int    `json:"matched_transactions"`
+	TimeframeStart                   string    `json:"timeframe_start"`
+	TimeframeEnd                     string    `json:"timeframe_end"`
+	ReportingCurrency                Currency  `json:"reporting_currency"`
+	TotalSystemTransactionsProcessed int       `json:"total_system_transactions_processed"`
+	TotalBankTransactionsProcessed   int       `json:"total_bank_transactions_processed"`
+	MatchedTransactions              int       `json:"matched_transactions"`
+	GroupedSystemTransactions        int       `json:"grouped_system_transactions"`
+	GroupedBankTransactions          int       `json:"grouped_bank_transactions"`
+	Balances                         *Balances `json:"balances,omitempty"`
 }
 
 // ReconciliationReport is the top-level structure for the final JSON output.
 type ReconciliationReport struct {
 	ReconciliationSummary  Summary                `json:"reconciliation_summary"`
 	DiscrepantTransactions DiscrepantTransactions `json:"discrepant_transactions"`
+	GroupedMatches         GroupedMatches         `json:"grouped_matches"`
 	UnmatchedTransactions  UnmatchedTransactions  `json:"unmatched_transactions"`
+	LaggedMatches          []MatchedPair          `json:"lagged_matches,omitempty"`    // matched pairs booked on different dates
+	AmbiguousMatches       []MatchedPair          `json:"ambiguous_matches,omitempty"` // pairs picked from groups of unequal size
+	MatchedTransactions    *MatchedTransactions   `json:"matched_transactions,omitempty"`
+	ManualMatches          []MatchedPair          `json:"manual_matches,omitempty"`       // pairs matched by overrides
+	IgnoredTransactions    *IgnoredTransactions   `json:"ignored_transactions,omitempty"` // transactions excluded by overrides
+	OverrideErrors         []OverrideError        `json:"override_errors,omitempty"`
+	OpenItems              *OpenItemsReport       `json:"open_items,omitempty"`       // carry-forward across periods
+	IngestionErrors        *IngestionErrors       `json:"ingestion_errors,omitempty"` // rows skipped by lenient parsing
 }
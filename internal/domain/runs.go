@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRunNotFound is returned when no reconciliation run has the requested ID.
+var ErrRunNotFound = errors.New("run not found")
+
+// RunStatus is the stage a reconciliation run has reached.
+type RunStatus string
+
+const (
+	RunQueued    RunStatus = "queued"
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// Finished reports whether the run has stopped, successfully or not.
+func (s RunStatus) Finished() bool {
+	return s == RunSucceeded || s == RunFailed
+}
+
+// Run is one asynchronous reconciliation, from submission to its report.
+type Run struct {
+	ID         string        `json:"id"`
+	Status     RunStatus     `json:"status"`
+	Parameters RunParameters `json:"parameters"`
+	Inputs     []RunInput    `json:"inputs"`
+	Error      string        `json:"error,omitempty"` // why a failed run failed
+	CreatedAt  time.Time     `json:"created_at"`
+	StartedAt  *time.Time    `json:"started_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
+
+// RunParameters are the reconciliation parameters a run was submitted with, so that a
+// historical run can be reproduced with the same settings.
+type RunParameters struct {
+	Start             string   `json:"start"` // YYYY-MM-DD
+	End               string   `json:"end"`   // YYYY-MM-DD
+	ReportingCurrency Currency `json:"reporting_currency,omitempty"`
+	SettlementLagDays int      `json:"settlement_lag_days"`
+	BusinessDays      bool     `json:"business_days,omitempty"` // the lag counts only Monday to Friday
+	ToleranceAbsolute *Decimal `json:"tolerance_abs,omitempty"`
+	TolerancePercent  *Decimal `json:"tolerance_pct,omitempty"`
+	AggregateMax      int      `json:"aggregate_max,omitempty"` // largest group summed by the aggregate pass
+	Passes            []string `json:"passes,omitempty"`        // matching passes in the order they run
+}
+
+// RunInput identifies an input file of a run by its checksum, so that a historical run can
+// be traced back to the exact files it reconciled.
+type RunInput struct {
+	Kind   string `json:"kind"` // "system" or "bank"
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// Source is a named input, such as a file, an upload or standard input. The name identifies
+// the source in errors and reports: bank transactions are reported under the base name of
+// their statement, which also selects the statement's bank profile.
+type Source struct {
+	Name string
+	// Open returns a reader of the content. It is called each time the source is read.
+	Open func() (io.ReadCloser, error)
+}
+
+// FileSource returns the source reading the file at path, named after the path.
+func FileSource(path string) Source {
+	return Source{Name: path, Open: func() (io.ReadCloser, error) { return os.Open(path) }}
+}
+
+// FileSources returns the sources reading the files at paths.
+func FileSources(paths []string) []Source {
+	sources := make([]Source, 0, len(paths))
+	for _, path := range paths {
+		sources = append(sources, FileSource(path))
+	}
+	return sources
+}
+
+// BytesSource returns the source reading an in-memory buffer.
+func BytesSource(name string, data []byte) Source {
+	return Source{Name: name, Open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }}
+}
+
+// ReaderSource returns the source reading r, such as standard input. Unlike the other
+// sources it can only be read once, while bank statements are read a second time when
+// balances are reconciled.
+func ReaderSource(name string, r io.Reader) Source {
+	var read atomic.Bool
+	return Source{Name: name, Open: func() (io.ReadCloser, error) {
+		if read.Swap(true) {
+			return nil, fmt.Errorf("%s can only be read once", name)
+		}
+		return io.NopCloser(r), nil
+	}}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "io"
+
+// Stream yields values one at a time, so that large inputs need not be held in memory.
+type Stream[T any] interface {
+	// Next returns the next value, or io.EOF once there are no more.
+	Next() (T, error)
+	// Close releases what the stream holds, such as temporary files.
+	Close() error
+}
+
+// SystemTransactionStream and BankTransactionStream are the streams of transactions read
+// from the inputs.
+type (
+	SystemTransactionStream = Stream[SystemTransaction]
+	BankTransactionStream   = Stream[BankTransaction]
+)
+
+// SliceStream returns the stream of the values in items, in order.
+func SliceStream[T any](items []T) Stream[T] {
+	return &sliceStream[T]{items: items}
+}
+
+type sliceStream[T any] struct {
+	items []T
+}
+
+func (s *sliceStream[T]) Next() (T, error) {
+	var next T
+	if len(s.items) == 0 {
+		return next, io.EOF
+	}
+	next, s.items = s.items[0], s.items[1:]
+	return next, nil
+}
+
+func (s *sliceStream[T]) Close() error {
+	s.items = nil
+	return nil
+}
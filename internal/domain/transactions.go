@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // TransactionType defines the nature of the transaction (DEBIT or CREDIT).
 type TransactionType string
@@ -13,7 +16,8 @@ const (
 // SystemTransaction represents a transaction from Amartha's internal system.
 type SystemTransaction struct {
 	TrxID           string          `json:"trxID"`
-	Amount          float64         `json:"amount"`
+	Amount          Decimal         `json:"amount"`
+	Currency        Currency        `json:"currency"`
 	Type            TransactionType `json:"type"`
 	TransactionTime time.Time       `json:"transactionTime"`
 }
@@ -22,12 +26,34 @@ type SystemTransaction struct {
 // It includes additional fields for normalization and tracking.
 type BankTransaction struct {
 	UniqueIdentifier string    `json:"unique_identifier"`
-	Amount           float64   `json:"amount"` // Can be negative
+	Amount           Decimal   `json:"amount"` // Can be negative
+	Currency         Currency  `json:"currency"`
 	Date             time.Time `json:"date"`
 	Description      string    `json:"description"`
 	BankSource       string    `json:"bank_source"` // e.g., "bank_A_statement.csv"
 
 	// Normalized fields for reconciliation logic
-	NormalizedAmount float64         `json:"-"`
+	NormalizedAmount Decimal         `json:"-"`
 	Type             TransactionType `json:"-"`
+	// ReferenceFields holds the statement columns that may carry a system reference,
+	// besides the description (e.g. a "Customer Ref" column)
+	ReferenceFields []string `json:"-"`
+}
+
+// MarshalJSON encodes the transaction with its amount at the scale of its currency.
+func (t SystemTransaction) MarshalJSON() ([]byte, error) {
+	type fields SystemTransaction // drops the method, keeping the fields and their tags
+	return json.Marshal(struct {
+		fields
+		Amount json.RawMessage `json:"amount"`
+	}{fields(t), t.Currency.amountJSON(t.Amount)})
+}
+
+// MarshalJSON encodes the transaction with its amount at the scale of its currency.
+func (t BankTransaction) MarshalJSON() ([]byte, error) {
+	type fields BankTransaction
+	return json.Marshal(struct {
+		fields
+		Amount json.RawMessage `json:"amount"`
+	}{fields(t), t.Currency.amountJSON(t.Amount)})
 }
@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Columns of the statement balances CSV file.
+const (
+	ColumnBalanceBankSource = "bank_source"
+	ColumnBalanceOpening    = "opening_balance"
+	ColumnBalanceClosing    = "closing_balance"
+	ColumnBalanceCurrency   = "currency"
+)
+
+// LoadBalances reads statement balances from a CSV file with the columns bank_source
+// (the statement file name), opening_balance and closing_balance. Either balance may be
+// left empty. An optional currency column gives the statement's currency; without it the
+// balances are taken to be in the currency of the statement's transactions.
+func LoadBalances(path string) ([]domain.StatementBalance, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open balances file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header from %s: %w", path, err)
+	}
+	cols, err := resolveColumns(path, header, nil, []string{ColumnBalanceBankSource, ColumnBalanceOpening, ColumnBalanceClosing}, []string{ColumnBalanceCurrency})
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []domain.StatementBalance
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading record from %s: %w", path, err)
+		}
+
+		balance := domain.StatementBalance{BankSource: strings.TrimSpace(record[cols[ColumnBalanceBankSource]])}
+		if code := cols.value(record, ColumnBalanceCurrency); strings.TrimSpace(code) != "" {
+			balance.Currency = domain.ParseCurrency(code)
+		}
+		if balance.Opening, err = parseOptionalBalance(record[cols[ColumnBalanceOpening]]); err != nil {
+			return nil, fmt.Errorf("could not parse opening_balance '%s': %w", record[cols[ColumnBalanceOpening]], err)
+		}
+		if balance.Closing, err = parseOptionalBalance(record[cols[ColumnBalanceClosing]]); err != nil {
+			return nil, fmt.Errorf("could not parse closing_balance '%s': %w", record[cols[ColumnBalanceClosing]], err)
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+func parseOptionalBalance(raw string) (*domain.Decimal, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	balance, err := domain.ParseDecimal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadBalances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "balances.csv")
+	writeFile(t, path, "bank_source,opening_balance,closing_balance\n"+
+		"statement_bank_A.csv,1000.00,1125.50\n"+
+		"statement_bank_B.csv,,-20\n")
+
+	got, err := LoadBalances(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.StatementBalance{
+		{BankSource: "statement_bank_A.csv", Opening: decimalPtr("1000"), Closing: decimalPtr("1125.5")},
+		{BankSource: "statement_bank_B.csv", Closing: decimalPtr("-20")},
+	}, got)
+
+	t.Run("currency column", func(t *testing.T) {
+		withCurrency := filepath.Join(t.TempDir(), "balances.csv")
+		writeFile(t, withCurrency, "bank_source,currency,opening_balance,closing_balance\n"+
+			"statement_usd.csv,usd,10,12.5\n"+
+			"statement_bank_A.csv,,1000,1000\n")
+		got, err := LoadBalances(withCurrency)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.StatementBalance{
+			{BankSource: "statement_usd.csv", Currency: "USD", Opening: decimalPtr("10"), Closing: decimalPtr("12.5")},
+			{BankSource: "statement_bank_A.csv", Opening: decimalPtr("1000"), Closing: decimalPtr("1000")},
+		}, got)
+	})
+
+	t.Run("bad balance", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "balances.csv")
+		writeFile(t, bad, "bank_source,opening_balance,closing_balance\nbank.csv,abc,\n")
+		_, err := LoadBalances(bad)
+		assert.ErrorContains(t, err, "could not parse opening_balance 'abc'")
+	})
+}
+
+func TestCSVTransactionRepository_GetBankBalances(t *testing.T) {
+	profiles := &ProfileSet{Profiles: []BankProfile{
+		{
+			Name:                "rows",
+			FilePattern:         "rows_*.csv",
+			SignConvention:      SignDebitPositive,
+			OpeningBalanceLabel: "Opening Balance",
+			ClosingBalanceLabel: "Closing Balance",
+		},
+	}}
+
+	dir := t.TempDir()
+	withRows := filepath.Join(dir, "rows_september.csv")
+	writeFile(t, withRows, "unique_identifier,amount,date,description\n"+
+		",1000.00,,OPENING BALANCE\n"+
+		"R_1,150.00,2025-09-01,Payment\n"+
+		",850.00,,Closing Balance\n")
+	without := filepath.Join(dir, "plain.csv")
+	writeFile(t, without, "unique_identifier,amount,date,description\n"+
+		"P_1,-10.00,2025-09-01,Opening Balance\n")
+
+	repo := NewCSVTransactionRepository(WithProfiles(profiles))
+	got, err := repo.GetBankBalances(context.Background(), domain.FileSources([]string{withRows, without}))
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.StatementBalance{
+		{BankSource: "rows_september.csv", Currency: domain.DefaultCurrency, Opening: decimalPtr("1000"), Closing: decimalPtr("850")},
+	}, got)
+
+	// Balance rows are not transactions; statements without labels keep every row
+	txs, err := repo.GetBankTransactions(context.Background(), domain.FileSources([]string{withRows, without}))
+	assert.NoError(t, err)
+	if assert.Len(t, txs, 2) {
+		assert.Equal(t, "R_1", txs[0].UniqueIdentifier)
+		assert.Equal(t, domain.MustParseDecimal("-150"), txs[0].Amount)
+		assert.Equal(t, "P_1", txs[1].UniqueIdentifier)
+	}
+
+	// The balances of an upload come from the read of its transactions, as it cannot be
+	// read twice
+	upload := domain.ReaderSource("upload/rows_october.csv", strings.NewReader("unique_identifier,amount,date,description\n"+
+		",850.00,,Opening Balance\n"+
+		"R_2,-50.00,2025-10-01,Refund\n"+
+		",900.00,,Closing Balance\n"))
+	txs, err = repo.GetBankTransactions(context.Background(), []domain.Source{upload})
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	got, err = repo.GetBankBalances(context.Background(), []domain.Source{upload})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.StatementBalance{
+		{BankSource: "rows_october.csv", Currency: domain.DefaultCurrency, Opening: decimalPtr("850"), Closing: decimalPtr("900")},
+	}, got)
+}
+
+func decimalPtr(s string) *domain.Decimal {
+	d := domain.MustParseDecimal(s)
+	return &d
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Canonical column names understood by the CSV repository.
+const (
+	ColumnTrxID            = "trxID"
+	ColumnType             = "type"
+	ColumnTransactionTime  = "transactionTime"
+	ColumnUniqueIdentifier = "unique_identifier"
+	ColumnAmount           = "amount"
+	ColumnDate             = "date"
+	ColumnDescription      = "description"
+	ColumnCurrency         = "currency"
+)
+
+var (
+	systemRequiredColumns = []string{ColumnTrxID, ColumnAmount, ColumnType, ColumnTransactionTime}
+	bankRequiredColumns   = []string{ColumnUniqueIdentifier, ColumnAmount, ColumnDate, ColumnDescription}
+	optionalColumns       = []string{ColumnCurrency}
+)
+
+// ColumnMapping maps a canonical column name to the header used by a CSV source,
+// e.g. {"amount": "Debit/Credit Amount"}. Unmapped columns are looked up by their canonical name.
+type ColumnMapping map[string]string
+
+// headerName returns the header expected for the canonical column.
+func (m ColumnMapping) headerName(column string) string {
+	if name, ok := m[column]; ok && name != "" {
+		return name
+	}
+	return column
+}
+
+// columnIndex holds the resolved position of each canonical column within a record.
+type columnIndex map[string]int
+
+// value returns the field of record for the canonical column, or "" if the source lacks that column.
+func (c columnIndex) value(record []string, column string) string {
+	pos, ok := c[column]
+	if !ok {
+		return ""
+	}
+	return record[pos]
+}
+
+// resolveColumns locates the required and any present optional canonical columns in the
+// header row. Header names are compared case-insensitively and ignoring surrounding whitespace.
+func resolveColumns(path string, header []string, mapping ColumnMapping, required, optional []string) (columnIndex, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		key := normalizeHeader(name)
+		if _, exists := positions[key]; !exists {
+			positions[key] = i
+		}
+	}
+
+	index := make(columnIndex, len(required))
+	for _, column := range required {
+		name := mapping.headerName(column)
+		pos, ok := positions[normalizeHeader(name)]
+		if !ok {
+			return nil, domain.InvalidInput(fmt.Errorf("file %s is missing required column %q (header %q)", path, column, name))
+		}
+		index[column] = pos
+	}
+	for _, column := range optional {
+		if pos, ok := positions[normalizeHeader(mapping.headerName(column))]; ok {
+			index[column] = pos
+		}
+	}
+	return index, nil
+}
+
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
@@ -3,125 +3,528 @@ package gateway
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"math"
-	"os"
 	"path/filepath"
-	"strconv"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"mini-reconciliation/internal/domain"
 )
 
 // CSVTransactionRepository implements the TransactionRepository interface for CSV files.
-type CSVTransactionRepository struct{}
+type CSVTransactionRepository struct {
+	systemColumns ColumnMapping
+	bankColumns   map[string]ColumnMapping
+	profiles      *ProfileSet
+	assignments   map[string]string
+	sortBuffer    int
+	parseWorkers  int
+
+	lenient     bool
+	maxRejected int
+	mu          sync.Mutex
+	rejected    []domain.IngestionError
+	seen        map[string]bool                    // rejected rows by file and line, as statements may be read more than once
+	balances    map[string]domain.StatementBalance // balance rows found by the last read of each statement, by source name
+}
+
+// Option configures a CSVTransactionRepository.
+type Option func(*CSVTransactionRepository)
+
+// WithSystemColumns sets the header mapping used for the system transactions file.
+func WithSystemColumns(mapping ColumnMapping) Option {
+	return func(r *CSVTransactionRepository) {
+		r.systemColumns = mapping
+	}
+}
+
+// WithBankColumns sets the header mapping used for the bank statement with the given
+// file name (e.g. "statement_bank_A.csv"). An empty source applies to every bank statement
+// that has no mapping of its own.
+func WithBankColumns(source string, mapping ColumnMapping) Option {
+	return func(r *CSVTransactionRepository) {
+		r.bankColumns[source] = mapping
+	}
+}
+
+// WithProfiles sets the bank profiles used to parse bank statements. A statement uses the
+// profile explicitly assigned to it, otherwise the first profile whose file pattern matches.
+func WithProfiles(profiles *ProfileSet) Option {
+	return func(r *CSVTransactionRepository) {
+		r.profiles = profiles
+	}
+}
+
+// WithProfileAssignment assigns the named profile to the bank statement source named path,
+// taking precedence over file pattern matching.
+func WithProfileAssignment(path, profile string) Option {
+	return func(r *CSVTransactionRepository) {
+		r.assignments[path] = profile
+	}
+}
+
+// WithLenientParsing makes the repository skip the rows it cannot read instead of failing,
+// collecting them for IngestionErrors. Reading still fails once more than maxRejected rows
+// have been skipped across all files; zero means no limit.
+func WithLenientParsing(maxRejected int) Option {
+	return func(r *CSVTransactionRepository) {
+		r.lenient = true
+		r.maxRejected = maxRejected
+	}
+}
+
+// WithParseWorkers sets how many bank statements are parsed at once. Zero or less uses one
+// worker per CPU.
+func WithParseWorkers(workers int) Option {
+	return func(r *CSVTransactionRepository) {
+		r.parseWorkers = workers
+	}
+}
 
 // NewCSVTransactionRepository creates a new repository instance.
-func NewCSVTransactionRepository() *CSVTransactionRepository {
-	return &CSVTransactionRepository{}
+func NewCSVTransactionRepository(opts ...Option) *CSVTransactionRepository {
+	r := &CSVTransactionRepository{
+		bankColumns: make(map[string]ColumnMapping),
+		assignments: make(map[string]string),
+		sortBuffer:  DefaultSortBuffer,
+		seen:        make(map[string]bool),
+		balances:    make(map[string]domain.StatementBalance),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// GetSystemTransactions reads and parses the system transactions CSV source.
+func (r *CSVTransactionRepository) GetSystemTransactions(ctx context.Context, source domain.Source) ([]domain.SystemTransaction, error) {
+	var transactions []domain.SystemTransaction
+	err := r.scanSystem(source, r.onRowError(), func(tx domain.SystemTransaction) error {
+		transactions = append(transactions, tx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
 }
 
-// GetSystemTransactions reads and parses the system transactions CSV file.
-func (r *CSVTransactionRepository) GetSystemTransactions(ctx context.Context, path string) ([]domain.SystemTransaction, error) {
-	file, err := os.Open(path)
+// scanSystem parses the system transactions source row by row, passing each transaction
+// to onTx and rows that cannot be read to onRowError.
+func (r *CSVTransactionRepository) scanSystem(source domain.Source, onRowError rowErrorHandler, onTx func(domain.SystemTransaction) error) error {
+	path := source.Name
+	file, err := source.Open()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open system transaction file %s: %w", path, err)
+		return fmt.Errorf("failed to open system transaction file %s: %w", path, err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
-	// Skip header
-	if _, err := reader.Read(); err != nil {
-		return nil, fmt.Errorf("failed to read header from %s: %w", path, err)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return headerError(path, err)
+	}
+	cols, err := resolveColumns(path, header, r.systemColumns, systemRequiredColumns, optionalColumns)
+	if err != nil {
+		return err
 	}
 
-	var transactions []domain.SystemTransaction
 	for {
-		record, err := reader.Read()
+		record, line, err := readRecord(reader, path, onRowError)
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading record from %s: %w", path, err)
+			return err
+		}
+		if record == nil {
+			continue
 		}
 
-		amount, err := strconv.ParseFloat(record[1], 64)
+		tx, err := parseSystemRecord(record, cols)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse amount '%s': %w", record[1], err)
+			if err := onRowError(rowError(path, line, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := onTx(tx); err != nil {
+			return err
 		}
+	}
+}
 
-		txTime, err := time.Parse(time.RFC3339, record[3])
-		if err != nil {
-			return nil, fmt.Errorf("could not parse transactionTime '%s': %w", record[3], err)
+func parseSystemRecord(record []string, cols columnIndex) (domain.SystemTransaction, error) {
+	currency := domain.ParseCurrency(cols.value(record, ColumnCurrency))
+	amount, err := currency.ParseAmount(record[cols[ColumnAmount]])
+	if err != nil {
+		return domain.SystemTransaction{}, &fieldError{column: ColumnAmount, value: record[cols[ColumnAmount]], err: err}
+	}
+
+	txType := domain.TransactionType(record[cols[ColumnType]])
+	if txType != domain.TransactionTypeDebit && txType != domain.TransactionTypeCredit {
+		return domain.SystemTransaction{}, &fieldError{column: ColumnType, value: record[cols[ColumnType]], err: errInvalidType}
+	}
+
+	txTime, err := time.Parse(time.RFC3339, record[cols[ColumnTransactionTime]])
+	if err != nil {
+		return domain.SystemTransaction{}, &fieldError{column: ColumnTransactionTime, value: record[cols[ColumnTransactionTime]], err: err}
+	}
+
+	return domain.SystemTransaction{
+		TrxID:           record[cols[ColumnTrxID]],
+		Amount:          amount,
+		Currency:        currency,
+		Type:            txType,
+		TransactionTime: txTime,
+	}, nil
+}
+
+// GetBankTransactions reads and parses multiple bank statement CSV sources, several at
+// once (see WithParseWorkers). Transactions are returned in the order of the sources.
+func (r *CSVTransactionRepository) GetBankTransactions(ctx context.Context, sources []domain.Source) ([]domain.BankTransaction, error) {
+	transactions := make([][]domain.BankTransaction, len(sources))
+	err := r.eachStatement(ctx, sources, func(i int, source domain.Source) error {
+		return r.readStatement(source, func(tx domain.BankTransaction) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			transactions[i] = append(transactions[i], tx)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var allTransactions []domain.BankTransaction
+	for _, statement := range transactions {
+		allTransactions = append(allTransactions, statement...)
+	}
+	return allTransactions, nil
+}
+
+// GetBankBalances returns the opening and closing balances found in the balance rows of
+// the bank statements. Statements without balance rows are left out. The balances of
+// statements already read for their transactions are those found then, so that read-once
+// sources are not read again; the other statements are read now.
+func (r *CSVTransactionRepository) GetBankBalances(ctx context.Context, sources []domain.Source) ([]domain.StatementBalance, error) {
+	var unread []domain.Source
+	r.mu.Lock()
+	for _, source := range sources {
+		if _, ok := r.balances[source.Name]; !ok {
+			unread = append(unread, source)
 		}
+	}
+	r.mu.Unlock()
+	err := r.eachStatement(ctx, unread, func(i int, source domain.Source) error {
+		return r.readStatement(source, func(domain.BankTransaction) error {
+			return ctx.Err()
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		tx := domain.SystemTransaction{
-			TrxID:           record[0],
-			Amount:          amount,
-			Type:            domain.TransactionType(record[2]),
-			TransactionTime: txTime,
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var balances []domain.StatementBalance
+	for _, source := range sources {
+		if balance := r.balances[source.Name]; balance.Opening != nil || balance.Closing != nil {
+			balances = append(balances, balance)
 		}
-		transactions = append(transactions, tx)
 	}
-	return transactions, nil
+	return balances, nil
 }
 
-// GetBankTransactions reads and parses multiple bank statement CSV files.
-func (r *CSVTransactionRepository) GetBankTransactions(ctx context.Context, paths []string) ([]domain.BankTransaction, error) {
-	var allTransactions []domain.BankTransaction
+// readStatement scans the bank statement source for the repository, recording the
+// balance rows it holds for GetBankBalances.
+func (r *CSVTransactionRepository) readStatement(source domain.Source, onTx func(domain.BankTransaction) error) error {
+	balance, err := r.scanStatement(source, r.onRowError(), onTx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.balances[source.Name] = balance
+	r.mu.Unlock()
+	return nil
+}
 
-	for _, path := range paths {
-		file, err := os.Open(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open bank statement file %s: %w", path, err)
+// eachStatement calls read for every bank statement source, with at most the configured
+// number of statements being read at once. Every statement is read even when others fail,
+// and the failures are returned together in the order of the sources. Once ctx is done no
+// more statements are started and its error is returned.
+func (r *CSVTransactionRepository) eachStatement(ctx context.Context, sources []domain.Source, read func(i int, source domain.Source) error) error {
+	workers := r.parseWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(sources))
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
 		}
-		defer file.Close()
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, source domain.Source) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = read(i, source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// scanStatement parses the bank statement source with its profile row by row, passing
+// each transaction to onTx and rows that cannot be read to onRowError. Rows labelled as
+// the opening or closing balance by the profile are returned as the statement balance,
+// not passed on as transactions.
+func (r *CSVTransactionRepository) scanStatement(source domain.Source, onRowError rowErrorHandler, onTx func(domain.BankTransaction) error) (domain.StatementBalance, error) {
+	path := source.Name
+	balance := domain.StatementBalance{BankSource: filepath.Base(path)}
+	profile, err := r.bankProfileFor(path)
+	if err != nil {
+		return balance, err
+	}
+
+	file, err := source.Open()
+	if err != nil {
+		return balance, fmt.Errorf("failed to open bank statement file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = profile.delimiter()
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return balance, headerError(path, err)
+	}
+	cols, err := resolveColumns(path, header, profile.Columns, profile.requiredColumns(), optionalColumns)
+	if err != nil {
+		return balance, err
+	}
+	refCols, err := resolveColumns(path, header, nil, profile.ReferenceColumns, nil)
+	if err != nil {
+		return balance, err
+	}
 
-		reader := csv.NewReader(file)
-		// Skip header
-		if _, err := reader.Read(); err != nil {
-			return nil, fmt.Errorf("failed to read header from %s: %w", path, err)
+	for {
+		record, line, err := readRecord(reader, path, onRowError)
+		if err == io.EOF {
+			return balance, nil
+		}
+		if err != nil {
+			return balance, err
+		}
+		if record == nil {
+			continue
 		}
 
-		for {
-			record, err := reader.Read()
-			if err == io.EOF {
-				break
-			}
+		if kind := profile.balanceRow(record[cols[ColumnDescription]]); kind != noBalance {
+			amount, err := profile.parseBalance(record, cols)
 			if err != nil {
-				return nil, fmt.Errorf("error reading record from %s: %w", path, err)
+				if err := onRowError(rowError(path, line, err)); err != nil {
+					return balance, err
+				}
+				continue
 			}
-
-			amount, err := strconv.ParseFloat(record[1], 64)
-			if err != nil {
-				return nil, fmt.Errorf("could not parse amount '%s': %w", record[1], err)
+			balance.Currency = profile.currency(cols.value(record, ColumnCurrency))
+			if kind == openingBalance {
+				balance.Opening = &amount
+			} else {
+				balance.Closing = &amount
 			}
+			continue
+		}
 
-			date, err := time.Parse("2006-01-02", record[2])
-			if err != nil {
-				return nil, fmt.Errorf("could not parse date '%s': %w", record[2], err)
+		tx, err := profile.parseRecord(record, cols, refCols)
+		if err != nil {
+			if err := onRowError(rowError(path, line, err)); err != nil {
+				return balance, err
 			}
+			continue
+		}
+		tx.BankSource = filepath.Base(path)
+		if err := onTx(tx); err != nil {
+			return balance, err
+		}
+	}
+}
 
-			tx := domain.BankTransaction{
-				UniqueIdentifier: record[0],
-				Amount:           amount,
-				Date:             date,
-				Description:      record[3],
-				BankSource:       filepath.Base(path),
-			}
+// parseRecord builds the bank transaction held by a statement record.
+func (p BankProfile) parseRecord(record []string, cols, refCols columnIndex) (domain.BankTransaction, error) {
+	currency := p.currency(cols.value(record, ColumnCurrency))
+	amount, err := p.parseAmount(record, cols, currency)
+	if err != nil {
+		return domain.BankTransaction{}, err
+	}
 
-			// Normalize the transaction for easier matching
-			if tx.Amount < 0 {
-				tx.Type = domain.TransactionTypeDebit
-				tx.NormalizedAmount = math.Abs(tx.Amount)
-			} else {
-				tx.Type = domain.TransactionTypeCredit
-				tx.NormalizedAmount = tx.Amount
-			}
+	date, err := time.Parse(p.dateLayout(), strings.TrimSpace(record[cols[ColumnDate]]))
+	if err != nil {
+		return domain.BankTransaction{}, &fieldError{column: ColumnDate, value: record[cols[ColumnDate]], err: err}
+	}
+
+	tx := domain.BankTransaction{
+		UniqueIdentifier: record[cols[ColumnUniqueIdentifier]],
+		Amount:           amount,
+		Currency:         currency,
+		Date:             date,
+		Description:      record[cols[ColumnDescription]],
+	}
+	for _, column := range p.ReferenceColumns {
+		tx.ReferenceFields = append(tx.ReferenceFields, record[refCols[column]])
+	}
+
+	// Normalize the transaction for easier matching
+	normalizeBankTransaction(&tx)
+	return tx, nil
+}
+
+// normalizeBankTransaction derives the type and unsigned amount of a bank transaction
+// from the sign of its statement amount.
+func normalizeBankTransaction(tx *domain.BankTransaction) {
+	if tx.Amount.Sign() < 0 {
+		tx.Type = domain.TransactionTypeDebit
+		tx.NormalizedAmount = tx.Amount.Abs()
+	} else {
+		tx.Type = domain.TransactionTypeCredit
+		tx.NormalizedAmount = tx.Amount
+	}
+}
+
+// IngestionErrors returns the rows skipped so far by lenient parsing.
+func (r *CSVTransactionRepository) IngestionErrors() []domain.IngestionError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.IngestionError(nil), r.rejected...)
+}
+
+// onRowError returns how the repository handles rows it cannot read.
+func (r *CSVTransactionRepository) onRowError() rowErrorHandler {
+	if r.lenient {
+		return r.reject
+	}
+	return failOnRowError
+}
+
+// reject records a row skipped by lenient parsing, failing once too many were skipped.
+func (r *CSVTransactionRepository) reject(rowErr domain.IngestionError) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", rowErr.File, rowErr.Line)
+	if !r.seen[key] {
+		r.seen[key] = true
+		r.rejected = append(r.rejected, rowErr)
+	}
+	if r.maxRejected > 0 && len(r.rejected) > r.maxRejected {
+		return fmt.Errorf("more than %d rows rejected, the last being %w", r.maxRejected, rowErr)
+	}
+	return nil
+}
+
+// headerError describes the failure to read the header of path. An empty file or a
+// malformed header is invalid input, unlike a failure to read the file.
+func headerError(path string, err error) error {
+	err = fmt.Errorf("failed to read header from %s: %w", path, err)
+	var parseErr *csv.ParseError
+	if errors.Is(err, io.EOF) || errors.As(err, &parseErr) {
+		return domain.InvalidInput(err)
+	}
+	return err
+}
+
+// rowErrorHandler decides what happens to a row that cannot be read: returning an error
+// aborts reading the file, returning nil skips the row.
+type rowErrorHandler func(domain.IngestionError) error
+
+// failOnRowError aborts reading at the first row that cannot be read.
+func failOnRowError(rowErr domain.IngestionError) error {
+	return rowErr
+}
+
+// readRecord reads the next record and the line it starts on. A malformed record is passed
+// to onRowError and, if skipped, returned as a nil record.
+func readRecord(reader *csv.Reader, path string, onRowError rowErrorHandler) ([]string, int, error) {
+	record, err := reader.Read()
+	if err == io.EOF {
+		return nil, 0, err
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) {
+			return nil, 0, fmt.Errorf("error reading record from %s: %w", path, err)
+		}
+		if err := onRowError(domain.IngestionError{File: path, Line: parseErr.StartLine, Reason: parseErr.Err.Error()}); err != nil {
+			return nil, parseErr.StartLine, err
+		}
+		return nil, parseErr.StartLine, nil
+	}
+	line, _ := reader.FieldPos(0)
+	return record, line, nil
+}
+
+// errInvalidType rejects a system transaction type other than DEBIT or CREDIT, which
+// would never match anything.
+var errInvalidType = fmt.Errorf("type must be %s or %s", domain.TransactionTypeDebit, domain.TransactionTypeCredit)
+
+// fieldError is a field value that could not be parsed.
+type fieldError struct {
+	column string
+	value  string
+	err    error
+}
+
+func (e *fieldError) Error() string {
+	return fmt.Sprintf("could not parse %s '%s': %v", e.column, e.value, e.err)
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// rowError describes the failure to read the record at line of path.
+func rowError(path string, line int, err error) domain.IngestionError {
+	var fieldErr *fieldError
+	if errors.As(err, &fieldErr) {
+		return domain.IngestionError{File: path, Line: line, Column: fieldErr.column, Value: fieldErr.value, Reason: fieldErr.err.Error()}
+	}
+	return domain.IngestionError{File: path, Line: line, Reason: err.Error()}
+}
 
-			allTransactions = append(allTransactions, tx)
+// bankProfileFor selects the profile used to parse the bank statement named path.
+// Statements without a profile use the default dialect with any configured column mapping.
+func (r *CSVTransactionRepository) bankProfileFor(path string) (BankProfile, error) {
+	if name, ok := r.assignments[path]; ok {
+		profile, found := r.profiles.Lookup(name)
+		if !found {
+			return BankProfile{}, fmt.Errorf("unknown bank profile %q for %s", name, path)
 		}
+		return profile, nil
 	}
-	return allTransactions, nil
+	if profile, ok := r.profiles.Match(path); ok {
+		return profile, nil
+	}
+
+	mapping, ok := r.bankColumns[filepath.Base(path)]
+	if !ok {
+		mapping = r.bankColumns[""]
+	}
+	return BankProfile{Columns: mapping}, nil
 }
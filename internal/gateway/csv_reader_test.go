@@ -3,8 +3,10 @@ package gateway
 import (
 	"context"
 	"encoding/csv"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,19 +33,22 @@ func TestCSVTransactionRepository_GetSystemTransactions(t *testing.T) {
 			expected: []domain.SystemTransaction{
 				{
 					TrxID:           "SYS001",
-					Amount:          150.00,
+					Amount:          domain.MustParseDecimal("150.00"),
+					Currency:        domain.DefaultCurrency,
 					Type:            domain.TransactionType("DEBIT"),
 					TransactionTime: mustParseTime("2025-09-01T10:00:00Z"),
 				},
 				{
 					TrxID:           "SYS002",
-					Amount:          200.50,
+					Amount:          domain.MustParseDecimal("200.50"),
+					Currency:        domain.DefaultCurrency,
 					Type:            domain.TransactionType("CREDIT"),
 					TransactionTime: mustParseTime("2025-09-01T11:30:00Z"),
 				},
 				{
 					TrxID:           "SYS003",
-					Amount:          75.00,
+					Amount:          domain.MustParseDecimal("75.00"),
+					Currency:        domain.DefaultCurrency,
 					Type:            domain.TransactionType("DEBIT"),
 					TransactionTime: mustParseTime("2025-09-02T09:00:00Z"),
 				},
@@ -67,6 +72,16 @@ func TestCSVTransactionRepository_GetSystemTransactions(t *testing.T) {
 			expected: nil,
 			wantErr:  true,
 		},
+		{
+			name: "amount past the currency's minor unit",
+			csvData: [][]string{
+				{"trxID", "amount", "type", "transactionTime"},
+				{"SYS001", "1000000000", "DEBIT", "2025-09-01T10:00:00Z"},
+				{"SYS002", "0.0000000001", "DEBIT", "2025-09-01T10:00:00Z"},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
 		{
 			name: "invalid time format",
 			csvData: [][]string{
@@ -90,7 +105,7 @@ func TestCSVTransactionRepository_GetSystemTransactions(t *testing.T) {
 			repo := NewCSVTransactionRepository()
 			ctx := context.Background()
 
-			got, err := repo.GetSystemTransactions(ctx, tmpFile)
+			got, err := repo.GetSystemTransactions(ctx, domain.FileSource(tmpFile))
 			if tt.wantErr {
 				assert.Error(t, err, "Expected error but got nil")
 				assert.Nil(t, got)
@@ -107,7 +122,7 @@ func TestCSVTransactionRepository_GetSystemTransactions_FileErrors(t *testing.T)
 	ctx := context.Background()
 
 	t.Run("file not found", func(t *testing.T) {
-		_, err := repo.GetSystemTransactions(ctx, "nonexistent_file.csv")
+		_, err := repo.GetSystemTransactions(ctx, domain.FileSource("nonexistent_file.csv"))
 		if err == nil {
 			t.Error("Expected error for nonexistent file, got nil")
 		}
@@ -122,7 +137,7 @@ func TestCSVTransactionRepository_GetSystemTransactions_FileErrors(t *testing.T)
 		defer os.Remove(tmpFile.Name())
 		tmpFile.Close()
 
-		_, err = repo.GetSystemTransactions(ctx, tmpFile.Name())
+		_, err = repo.GetSystemTransactions(ctx, domain.FileSource(tmpFile.Name()))
 		if err == nil {
 			t.Error("Expected error for empty file, got nil")
 		}
@@ -149,30 +164,30 @@ func TestCSVTransactionRepository_GetBankTransactions(t *testing.T) {
 			expected: []domain.BankTransaction{
 				{
 					UniqueIdentifier: "BANK_A_1",
-					Amount:           -150.00,
+					Amount:           domain.MustParseDecimal("-150.00"),
 					Date:             mustParseDate("2025-09-01"),
 					Description:      "Payment for INV001 trxID:SYS001",
 					BankSource:       "test_bank_0.csv",
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 150.00,
+					NormalizedAmount: domain.MustParseDecimal("150.00"),
 				},
 				{
 					UniqueIdentifier: "BANK_A_2",
-					Amount:           200.50,
+					Amount:           domain.MustParseDecimal("200.50"),
 					Date:             mustParseDate("2025-09-01"),
 					Description:      "Incoming Transfer",
 					BankSource:       "test_bank_0.csv",
 					Type:             domain.TransactionTypeCredit,
-					NormalizedAmount: 200.50,
+					NormalizedAmount: domain.MustParseDecimal("200.50"),
 				},
 				{
 					UniqueIdentifier: "BANK_A_3",
-					Amount:           -75.00,
+					Amount:           domain.MustParseDecimal("-75.00"),
 					Date:             mustParseDate("2025-09-02"),
 					Description:      "Withdrawal ATM Central",
 					BankSource:       "test_bank_0.csv",
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 75.00,
+					NormalizedAmount: domain.MustParseDecimal("75.00"),
 				},
 			},
 			wantErr: false,
@@ -192,21 +207,21 @@ func TestCSVTransactionRepository_GetBankTransactions(t *testing.T) {
 			expected: []domain.BankTransaction{
 				{
 					UniqueIdentifier: "BANK_A_1",
-					Amount:           -150.00,
+					Amount:           domain.MustParseDecimal("-150.00"),
 					Date:             mustParseDate("2025-09-01"),
 					Description:      "Payment for INV001",
 					BankSource:       "test_bank_0.csv",
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 150.00,
+					NormalizedAmount: domain.MustParseDecimal("150.00"),
 				},
 				{
 					UniqueIdentifier: "BANK_B_1",
-					Amount:           500.00,
+					Amount:           domain.MustParseDecimal("500.00"),
 					Date:             mustParseDate("2025-09-03"),
 					Description:      "Deposit from Client X",
 					BankSource:       "test_bank_1.csv",
 					Type:             domain.TransactionTypeCredit,
-					NormalizedAmount: 500.00,
+					NormalizedAmount: domain.MustParseDecimal("500.00"),
 				},
 			},
 			wantErr: false,
@@ -231,6 +246,17 @@ func TestCSVTransactionRepository_GetBankTransactions(t *testing.T) {
 			expected: nil,
 			wantErr:  true,
 		},
+		{
+			name: "amount past the currency's minor unit",
+			filesData: [][]string{
+				{
+					"unique_identifier,amount,date,description",
+					"BANK_A_1,-150.001,2025-09-01,Payment",
+				},
+			},
+			expected: nil,
+			wantErr:  true,
+		},
 		{
 			name: "invalid date format",
 			filesData: [][]string{
@@ -266,7 +292,7 @@ func TestCSVTransactionRepository_GetBankTransactions(t *testing.T) {
 			repo := NewCSVTransactionRepository()
 			ctx := context.Background()
 
-			got, err := repo.GetBankTransactions(ctx, tmpFiles)
+			got, err := repo.GetBankTransactions(ctx, domain.FileSources(tmpFiles))
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetBankTransactions() error = %v, wantErr %v", err, tt.wantErr)
@@ -300,7 +326,7 @@ func TestCSVTransactionRepository_GetBankTransactions_FileErrors(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("file not found", func(t *testing.T) {
-		_, err := repo.GetBankTransactions(ctx, []string{"nonexistent_file.csv"})
+		_, err := repo.GetBankTransactions(ctx, domain.FileSources([]string{"nonexistent_file.csv"}))
 		if err == nil {
 			t.Error("Expected error for nonexistent file, got nil")
 		}
@@ -317,13 +343,236 @@ func TestCSVTransactionRepository_GetBankTransactions_FileErrors(t *testing.T) {
 		}
 		defer os.Remove(validFile)
 
-		_, err = repo.GetBankTransactions(ctx, []string{validFile, "nonexistent.csv"})
+		_, err = repo.GetBankTransactions(ctx, domain.FileSources([]string{validFile, "nonexistent.csv"}))
 		if err == nil {
 			t.Error("Expected error when one file doesn't exist, got nil")
 		}
 	})
 }
 
+func TestCSVTransactionRepository_GetBankTransactions_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 12; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("bank_%02d.csv", i))
+		writeFile(t, path, fmt.Sprintf("unique_identifier,amount,date,description\n"+
+			"B%02d_1,-%d.00,2025-09-01,Payment\n"+
+			"B%02d_2,%d.50,2025-09-02,Refund\n", i, i+1, i, i+1))
+		paths = append(paths, path)
+	}
+
+	t.Run("keeps the order of the statements", func(t *testing.T) {
+		for _, workers := range []int{0, 1, 3} {
+			repo := NewCSVTransactionRepository(WithParseWorkers(workers))
+			txs, err := repo.GetBankTransactions(ctx, domain.FileSources(paths))
+			assert.NoError(t, err)
+			var ids []string
+			for _, tx := range txs {
+				ids = append(ids, tx.UniqueIdentifier)
+			}
+			var want []string
+			for i := range paths {
+				want = append(want, fmt.Sprintf("B%02d_1", i), fmt.Sprintf("B%02d_2", i))
+			}
+			assert.Equal(t, want, ids, "workers %d", workers)
+		}
+	})
+
+	t.Run("lists every statement that failed", func(t *testing.T) {
+		badAmount := filepath.Join(dir, "bad_amount.csv")
+		writeFile(t, badAmount, "unique_identifier,amount,date,description\n"+
+			"BAD_1,abc,2025-09-01,Payment\n")
+		missing := filepath.Join(dir, "missing.csv")
+		repo := NewCSVTransactionRepository(WithParseWorkers(2))
+
+		_, err := repo.GetBankTransactions(ctx, domain.FileSources(append([]string{missing}, append(paths, badAmount)...)))
+		assert.EqualError(t, err, "failed to open bank statement file "+missing+": open "+missing+": no such file or directory\n"+
+			badAmount+`:2: could not parse amount 'abc': invalid decimal "abc"`)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		repo := NewCSVTransactionRepository(WithParseWorkers(2))
+
+		_, err := repo.GetBankTransactions(cancelled, domain.FileSources(paths))
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = repo.GetBankBalances(cancelled, domain.FileSources(paths))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCSVTransactionRepository_ReaderSources(t *testing.T) {
+	ctx := context.Background()
+	profiles := &ProfileSet{Profiles: []BankProfile{{Name: "semicolon", FilePattern: "bank_b*.csv", Delimiter: ";"}}}
+	repo := NewCSVTransactionRepository(WithProfiles(profiles))
+
+	system := domain.ReaderSource("upload/system.csv", strings.NewReader("trxID,amount,type,transactionTime\n"+
+		"SYS001,150.00,DEBIT,2025-09-01T10:00:00Z\n"))
+	systemTxs, err := repo.GetSystemTransactions(ctx, system)
+	assert.NoError(t, err)
+	assert.Len(t, systemTxs, 1)
+	// A reader can only be read once
+	_, err = repo.GetSystemTransactions(ctx, system)
+	assert.EqualError(t, err, "failed to open system transaction file upload/system.csv: upload/system.csv can only be read once")
+
+	// Statements are reported, and matched to a profile, by the base name of the source
+	bank := domain.BytesSource("upload/bank_b.csv", []byte("unique_identifier;amount;date;description\n"+
+		"BANK_B_1;-150.00;2025-09-01;Payment\n"))
+	for i := 0; i < 2; i++ {
+		bankTxs, err := repo.GetBankTransactions(ctx, []domain.Source{bank})
+		assert.NoError(t, err)
+		if assert.Len(t, bankTxs, 1) {
+			assert.Equal(t, "bank_b.csv", bankTxs[0].BankSource)
+		}
+	}
+}
+
+func TestCSVTransactionRepository_LenientParsing(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	systemFile := filepath.Join(dir, "system.csv")
+	writeFile(t, systemFile, "trxID,amount,type,transactionTime\n"+
+		"SYS001,150.00,DEBIT,2025-09-01T10:00:00Z\n"+
+		"SYS002,abc,DEBIT,2025-09-01T11:00:00Z\n"+
+		"SYS003,75.00,CREDIT,2025-09-02T09:00:00Z\n"+
+		"SYS004,75.00,debit,2025-09-02T10:00:00Z\n")
+	bankFile := filepath.Join(dir, "bank.csv")
+	writeFile(t, bankFile, "unique_identifier,amount,date,description\n"+
+		"BANK_1,-150.00,2025-09-01,Payment\n"+
+		"BANK_2,75.00,2025-13-02,Refund\n"+
+		"BANK_3,75.00\n")
+
+	t.Run("skips and collects invalid rows", func(t *testing.T) {
+		repo := NewCSVTransactionRepository(WithLenientParsing(0))
+		systemTxs, err := repo.GetSystemTransactions(ctx, domain.FileSource(systemFile))
+		assert.NoError(t, err)
+		bankTxs, err := repo.GetBankTransactions(ctx, domain.FileSources([]string{bankFile}))
+		assert.NoError(t, err)
+		// Reading a statement again does not report its rows twice
+		_, err = repo.GetBankBalances(ctx, domain.FileSources([]string{bankFile}))
+		assert.NoError(t, err)
+
+		if assert.Len(t, systemTxs, 2) {
+			assert.Equal(t, "SYS003", systemTxs[1].TrxID)
+		}
+		if assert.Len(t, bankTxs, 1) {
+			assert.Equal(t, "BANK_1", bankTxs[0].UniqueIdentifier)
+		}
+		assert.Equal(t, []domain.IngestionError{
+			{File: systemFile, Line: 3, Column: ColumnAmount, Value: "abc", Reason: `invalid decimal "abc"`},
+			{File: systemFile, Line: 5, Column: ColumnType, Value: "debit", Reason: "type must be DEBIT or CREDIT"},
+			{File: bankFile, Line: 3, Column: ColumnDate, Value: "2025-13-02", Reason: `parsing time "2025-13-02": month out of range`},
+			{File: bankFile, Line: 4, Reason: "wrong number of fields"},
+		}, repo.IngestionErrors())
+	})
+
+	t.Run("fails past the limit", func(t *testing.T) {
+		repo := NewCSVTransactionRepository(WithLenientParsing(3))
+		_, err := repo.GetSystemTransactions(ctx, domain.FileSource(systemFile))
+		assert.NoError(t, err)
+		_, err = repo.GetBankTransactions(ctx, domain.FileSources([]string{bankFile}))
+		assert.EqualError(t, err, "more than 3 rows rejected, the last being "+bankFile+":4: wrong number of fields")
+	})
+
+	t.Run("strict by default", func(t *testing.T) {
+		repo := NewCSVTransactionRepository()
+		_, err := repo.GetSystemTransactions(ctx, domain.FileSource(systemFile))
+		assert.EqualError(t, err, systemFile+`:3: could not parse amount 'abc': invalid decimal "abc"`)
+		assert.Empty(t, repo.IngestionErrors())
+	})
+}
+
+func TestCSVTransactionRepository_ColumnMapping(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("system columns reordered with extras", func(t *testing.T) {
+		tmpFile, err := createTempCSV([][]string{
+			{"Transaction Time", "Type", "Channel", "Amount", "TRXID", "Currency"},
+			{"2025-09-01T10:00:00Z", "DEBIT", "web", "150.00", "SYS001", "usd"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create temp CSV file: %v", err)
+		}
+		defer os.Remove(tmpFile)
+
+		repo := NewCSVTransactionRepository(WithSystemColumns(ColumnMapping{
+			ColumnTransactionTime: "Transaction Time",
+		}))
+		got, err := repo.GetSystemTransactions(ctx, domain.FileSource(tmpFile))
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SystemTransaction{
+			{
+				TrxID:           "SYS001",
+				Amount:          domain.MustParseDecimal("150.00"),
+				Currency:        domain.Currency("USD"),
+				Type:            domain.TransactionTypeDebit,
+				TransactionTime: mustParseTime("2025-09-01T10:00:00Z"),
+			},
+		}, got)
+	})
+
+	t.Run("bank columns mapped per source", func(t *testing.T) {
+		mapped, err := createTempCSVFromLines([]string{
+			"Posting Date,Reference,Narrative,Balance,Debit/Credit Amount",
+			"2025-09-01,BANK_A_1,Payment trxID:SYS001,1000.00,-150.00",
+		}, "mapped_bank.csv")
+		if err != nil {
+			t.Fatalf("Failed to create temp CSV file: %v", err)
+		}
+		defer os.Remove(mapped)
+		plain, err := createTempCSVFromLines([]string{
+			"date,amount,unique_identifier,description",
+			"2025-09-03,500.00,BANK_B_1,Deposit",
+		}, "plain_bank.csv")
+		if err != nil {
+			t.Fatalf("Failed to create temp CSV file: %v", err)
+		}
+		defer os.Remove(plain)
+
+		repo := NewCSVTransactionRepository(WithBankColumns("mapped_bank.csv", ColumnMapping{
+			ColumnUniqueIdentifier: "Reference",
+			ColumnAmount:           "Debit/Credit Amount",
+			ColumnDate:             "Posting Date",
+			ColumnDescription:      "Narrative",
+		}))
+		got, err := repo.GetBankTransactions(ctx, domain.FileSources([]string{mapped, plain}))
+		assert.NoError(t, err)
+		if assert.Len(t, got, 2) {
+			assert.True(t, compareBankTransactions(got[0], domain.BankTransaction{
+				UniqueIdentifier: "BANK_A_1",
+				Amount:           domain.MustParseDecimal("-150.00"),
+				Date:             mustParseDate("2025-09-01"),
+				Description:      "Payment trxID:SYS001",
+				BankSource:       "mapped_bank.csv",
+				Type:             domain.TransactionTypeDebit,
+				NormalizedAmount: domain.MustParseDecimal("150.00"),
+			}), "got %+v", got[0])
+			assert.Equal(t, "BANK_B_1", got[1].UniqueIdentifier)
+			assert.Equal(t, domain.MustParseDecimal("500.00"), got[1].Amount)
+		}
+	})
+
+	t.Run("missing required column", func(t *testing.T) {
+		tmpFile, err := createTempCSVFromLines([]string{
+			"unique_identifier,date,description",
+			"BANK_A_1,2025-09-01,Payment",
+		}, "missing_amount.csv")
+		if err != nil {
+			t.Fatalf("Failed to create temp CSV file: %v", err)
+		}
+		defer os.Remove(tmpFile)
+
+		repo := NewCSVTransactionRepository()
+		_, err = repo.GetBankTransactions(ctx, domain.FileSources([]string{tmpFile}))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), tmpFile)
+			assert.Contains(t, err.Error(), `"amount"`)
+		}
+	})
+}
+
 // Helper functions
 
 func createTempCSV(data [][]string) (string, error) {
@@ -397,12 +646,12 @@ func mustParseDate(dateStr string) time.Time {
 
 func compareBankTransactions(got, want domain.BankTransaction) bool {
 	return got.UniqueIdentifier == want.UniqueIdentifier &&
-		got.Amount == want.Amount &&
+		got.Amount.Equal(want.Amount) &&
 		got.Date.Equal(want.Date) &&
 		got.Description == want.Description &&
 		got.BankSource == want.BankSource &&
 		got.Type == want.Type &&
-		got.NormalizedAmount == want.NormalizedAmount
+		got.NormalizedAmount.Equal(want.NormalizedAmount)
 }
 
 // Benchmark tests
@@ -430,7 +679,7 @@ func BenchmarkGetSystemTransactions(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := repo.GetSystemTransactions(ctx, tmpFile)
+		_, err := repo.GetSystemTransactions(ctx, domain.FileSource(tmpFile))
 		if err != nil {
 			b.Fatalf("Error in benchmark: %v", err)
 		}
@@ -455,7 +704,7 @@ func BenchmarkGetBankTransactions(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := repo.GetBankTransactions(ctx, []string{tmpFile})
+		_, err := repo.GetBankTransactions(ctx, domain.FileSources([]string{tmpFile}))
 		if err != nil {
 			b.Fatalf("Error in benchmark: %v", err)
 		}
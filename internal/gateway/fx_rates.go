@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Columns of the FX rate CSV file.
+const (
+	ColumnFXDate = "date"
+	ColumnFXFrom = "from"
+	ColumnFXTo   = "to"
+	ColumnFXRate = "rate"
+)
+
+// inverseRateScale is the precision kept when a rate is derived from its inverse pair.
+const inverseRateScale = 10
+
+type currencyPair struct {
+	from, to domain.Currency
+}
+
+// CSVFXRateProvider serves daily exchange rates loaded from a CSV file with the
+// columns date,from,to,rate (e.g. "2025-09-01,USD,IDR,16450.50").
+type CSVFXRateProvider struct {
+	rates map[currencyPair][]domain.FXRate // sorted by date
+}
+
+// LoadFXRates reads a daily FX rate table from a CSV file.
+func LoadFXRates(path string) (*CSVFXRateProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FX rate file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header from %s: %w", path, err)
+	}
+	cols, err := resolveColumns(path, header, nil, []string{ColumnFXDate, ColumnFXFrom, ColumnFXTo, ColumnFXRate}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &CSVFXRateProvider{rates: make(map[currencyPair][]domain.FXRate)}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading record from %s: %w", path, err)
+		}
+
+		date, err := time.Parse(defaultDateLayout, strings.TrimSpace(record[cols[ColumnFXDate]]))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse date '%s': %w", record[cols[ColumnFXDate]], err)
+		}
+		rate, err := domain.ParseDecimal(record[cols[ColumnFXRate]])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse rate '%s': %w", record[cols[ColumnFXRate]], err)
+		}
+		if rate.Sign() <= 0 {
+			return nil, fmt.Errorf("rate must be positive, got '%s'", record[cols[ColumnFXRate]])
+		}
+
+		fx := domain.FXRate{
+			From: domain.ParseCurrency(record[cols[ColumnFXFrom]]),
+			To:   domain.ParseCurrency(record[cols[ColumnFXTo]]),
+			Date: date,
+			Rate: rate,
+		}
+		pair := currencyPair{from: fx.From, to: fx.To}
+		p.rates[pair] = append(p.rates[pair], fx)
+	}
+
+	for _, rates := range p.rates {
+		sort.SliceStable(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+	}
+	return p, nil
+}
+
+// Rate returns the most recent rate published on or before date, so weekends and
+// holidays use the last business day's rate. When only the opposite pair is listed,
+// its inverse is used.
+func (p *CSVFXRateProvider) Rate(ctx context.Context, from, to domain.Currency, date time.Time) (domain.FXRate, error) {
+	if rate, ok := p.latest(from, to, date); ok {
+		return rate, nil
+	}
+	if inverse, ok := p.latest(to, from, date); ok {
+		return domain.FXRate{
+			From: from,
+			To:   to,
+			Date: inverse.Date,
+			Rate: domain.NewDecimalFromInt(1).Div(inverse.Rate, inverseRateScale),
+		}, nil
+	}
+	return domain.FXRate{}, domain.InvalidInput(fmt.Errorf("no FX rate from %s to %s on or before %s", from, to, date.Format(defaultDateLayout)))
+}
+
+func (p *CSVFXRateProvider) latest(from, to domain.Currency, date time.Time) (domain.FXRate, bool) {
+	rates := p.rates[currencyPair{from: from, to: to}]
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	// First rate dated after the day; the one before it is the latest applicable rate.
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date.After(day) })
+	if i == 0 {
+		return domain.FXRate{}, false
+	}
+	return rates[i-1], true
+}
@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFXRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fx.csv")
+	writeFile(t, path, "date,from,to,rate\n"+
+		"2025-09-02,USD,IDR,16500\n"+
+		"2025-09-01,usd,idr,16450.50\n"+
+		"2025-09-01,IDR,SGD,0.000078\n")
+
+	rates, err := LoadFXRates(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		from, to domain.Currency
+		date     string
+		wantRate string
+		wantDate string
+		wantErr  bool
+	}{
+		{name: "exact date", from: "USD", to: "IDR", date: "2025-09-01", wantRate: "16450.5", wantDate: "2025-09-01"},
+		{name: "falls back to latest earlier rate", from: "USD", to: "IDR", date: "2025-09-06", wantRate: "16500", wantDate: "2025-09-02"},
+		{name: "inverse pair", from: "SGD", to: "IDR", date: "2025-09-03", wantRate: "12820.5128205128", wantDate: "2025-09-01"},
+		{name: "before first rate", from: "USD", to: "IDR", date: "2025-08-31", wantErr: true},
+		{name: "unknown pair", from: "EUR", to: "IDR", date: "2025-09-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Rate(ctx, tt.from, tt.to, mustParseDate(tt.date))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRate, got.Rate.String())
+			assert.Equal(t, tt.wantDate, got.Date.Format(defaultDateLayout))
+			assert.Equal(t, tt.from, got.From)
+			assert.Equal(t, tt.to, got.To)
+		})
+	}
+}
+
+func TestLoadFXRates_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing column": "date,from,rate\n2025-09-01,USD,16450\n",
+		"bad rate":       "date,from,to,rate\n2025-09-01,USD,IDR,abc\n",
+		"zero rate":      "date,from,to,rate\n2025-09-01,USD,IDR,0\n",
+		"bad date":       "date,from,to,rate\n01/09/2025,USD,IDR,16450\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fx.csv")
+			writeFile(t, path, content)
+			_, err := LoadFXRates(path)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// JSONOpenItemStore keeps the open items of the last reconciliation period in a local JSON file.
+type JSONOpenItemStore struct {
+	path string
+}
+
+// NewJSONOpenItemStore creates a store backed by the file at path. The file is created on
+// the first save.
+func NewJSONOpenItemStore(path string) *JSONOpenItemStore {
+	return &JSONOpenItemStore{path: path}
+}
+
+// Load reads the stored open items. A missing file holds no items.
+func (s *JSONOpenItemStore) Load(ctx context.Context) (domain.OpenItems, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.OpenItems{}, nil
+	}
+	if err != nil {
+		return domain.OpenItems{}, fmt.Errorf("failed to open open items file %s: %w", s.path, err)
+	}
+
+	var items domain.OpenItems
+	if err := json.Unmarshal(data, &items); err != nil {
+		return domain.OpenItems{}, fmt.Errorf("could not parse open items file %s: %w", s.path, err)
+	}
+	for _, item := range items.Items {
+		if item.Bank != nil {
+			normalizeBankTransaction(item.Bank)
+		}
+	}
+	return items, nil
+}
+
+// Save replaces the stored open items. The file is written to a temporary file first and
+// renamed into place, so a failed run never leaves a truncated store behind.
+func (s *JSONOpenItemStore) Save(ctx context.Context, items domain.OpenItems) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode open items: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create open items file %s: %w", s.path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write open items file %s: %w", s.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write open items file %s: %w", s.path, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace open items file %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONOpenItemStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "open_items.json")
+	store := NewJSONOpenItemStore(path)
+
+	t.Run("missing file holds no items", func(t *testing.T) {
+		got, err := store.Load(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, got.Items)
+	})
+
+	items := domain.OpenItems{
+		AsOf: "2025-09-30",
+		Items: []domain.OpenItem{
+			{
+				System: &domain.SystemTransaction{
+					TrxID: "SYS030", Amount: domain.MustParseDecimal("75.5"), Currency: domain.DefaultCurrency,
+					Type: domain.TransactionTypeDebit, TransactionTime: mustParseDate("2025-09-30"),
+				},
+				FirstSeen: "2025-09-30",
+			},
+			{
+				Bank: &domain.BankTransaction{
+					UniqueIdentifier: "BANK_A_9", Amount: domain.MustParseDecimal("-20"), Currency: domain.DefaultCurrency,
+					Date: mustParseDate("2025-08-29"), Description: "FEE", BankSource: "statement_bank_A.csv",
+				},
+				References: []string{"INV-0829"},
+				FirstSeen:  "2025-08-31",
+			},
+		},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		assert.NoError(t, store.Save(ctx, items))
+
+		got, err := store.Load(ctx)
+		if !assert.NoError(t, err) || !assert.Len(t, got.Items, 2) {
+			return
+		}
+		assert.Equal(t, "2025-09-30", got.AsOf)
+		assert.Equal(t, *items.Items[0].System, *got.Items[0].System)
+
+		// Normalized fields are not stored and are derived again on load
+		bank := got.Items[1].Bank
+		assert.Equal(t, domain.TransactionTypeDebit, bank.Type)
+		assert.Equal(t, domain.MustParseDecimal("20"), bank.NormalizedAmount)
+		assert.Equal(t, []string{"INV-0829"}, got.Items[1].BankTransaction().ReferenceFields)
+		assert.Equal(t, "2025-08-31", got.Items[1].FirstSeen)
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "open_items.json")
+		writeFile(t, corrupt, "{")
+		_, err := NewJSONOpenItemStore(corrupt).Load(ctx)
+		assert.Error(t, err)
+	})
+}
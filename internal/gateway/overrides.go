@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Columns of the overrides CSV file.
+const (
+	ColumnOverrideAction     = "action"
+	ColumnOverrideSystemID   = "system_trx_id"
+	ColumnOverrideBankID     = "bank_unique_identifier"
+	ColumnOverrideBankSource = "bank_source"
+	ColumnOverrideNote       = "note"
+)
+
+// LoadOverrides reads manual overrides from a CSV (.csv) or JSON (.json) file. CSV files
+// have the columns system_trx_id and bank_unique_identifier, and optionally action
+// ("match" by default, or "ignore"), bank_source and note. JSON files hold an array of
+// objects with the same fields.
+func LoadOverrides(path string) ([]domain.Override, error) {
+	var overrides []domain.Override
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		overrides, err = readOverridesCSV(path)
+	case ".json":
+		overrides, err = readOverridesJSON(path)
+	default:
+		return nil, fmt.Errorf("unsupported overrides file format %s: expected .csv or .json", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range overrides {
+		o := &overrides[i]
+		o.Action = domain.OverrideAction(strings.ToLower(strings.TrimSpace(string(o.Action))))
+		if o.Action == "" {
+			o.Action = domain.OverrideMatch
+		}
+		if err := validateOverride(*o); err != nil {
+			return nil, fmt.Errorf("invalid override #%d in %s: %w", i+1, path, err)
+		}
+	}
+	return overrides, nil
+}
+
+func readOverridesCSV(path string) ([]domain.Override, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open overrides file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header from %s: %w", path, err)
+	}
+	cols, err := resolveColumns(path, header, nil,
+		[]string{ColumnOverrideSystemID, ColumnOverrideBankID},
+		[]string{ColumnOverrideAction, ColumnOverrideBankSource, ColumnOverrideNote})
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []domain.Override
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading record from %s: %w", path, err)
+		}
+		overrides = append(overrides, domain.Override{
+			Action:               domain.OverrideAction(cols.value(record, ColumnOverrideAction)),
+			SystemTrxID:          strings.TrimSpace(record[cols[ColumnOverrideSystemID]]),
+			BankUniqueIdentifier: strings.TrimSpace(record[cols[ColumnOverrideBankID]]),
+			BankSource:           strings.TrimSpace(cols.value(record, ColumnOverrideBankSource)),
+			Note:                 cols.value(record, ColumnOverrideNote),
+		})
+	}
+	return overrides, nil
+}
+
+func readOverridesJSON(path string) ([]domain.Override, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open overrides file %s: %w", path, err)
+	}
+	var overrides []domain.Override
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("could not parse overrides file %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+func validateOverride(o domain.Override) error {
+	switch o.Action {
+	case domain.OverrideMatch:
+		if o.SystemTrxID == "" || o.BankUniqueIdentifier == "" {
+			return fmt.Errorf("match needs both system_trx_id and bank_unique_identifier")
+		}
+	case domain.OverrideIgnore:
+		if o.SystemTrxID == "" && o.BankUniqueIdentifier == "" {
+			return fmt.Errorf("ignore needs system_trx_id or bank_unique_identifier")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", o.Action)
+	}
+	return nil
+}
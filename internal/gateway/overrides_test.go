@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	want := []domain.Override{
+		{Action: domain.OverrideMatch, SystemTrxID: "SYS017", BankUniqueIdentifier: "BANK_B_2", Note: "confirmed by ops"},
+		{Action: domain.OverrideIgnore, BankUniqueIdentifier: "BANK_A_9", BankSource: "statement_bank_A.csv"},
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     []domain.Override
+		wantErr  bool
+	}{
+		{
+			name:     "csv",
+			filename: "overrides.csv",
+			content: "system_trx_id,bank_unique_identifier,action,bank_source,note\n" +
+				"SYS017,BANK_B_2,,,confirmed by ops\n" +
+				" ,BANK_A_9,IGNORE,statement_bank_A.csv,\n",
+			want: want,
+		},
+		{
+			name:     "json",
+			filename: "overrides.json",
+			content: `[
+  {"system_trx_id": "SYS017", "bank_unique_identifier": "BANK_B_2", "note": "confirmed by ops"},
+  {"action": "ignore", "bank_unique_identifier": "BANK_A_9", "bank_source": "statement_bank_A.csv"}
+]`,
+			want: want,
+		},
+		{
+			name:     "match without bank",
+			filename: "overrides.csv",
+			content:  "system_trx_id,bank_unique_identifier\nSYS017,\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown action",
+			filename: "overrides.json",
+			content:  `[{"action": "merge", "system_trx_id": "SYS017"}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing column",
+			filename: "overrides.csv",
+			content:  "system_trx_id\nSYS017\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported extension",
+			filename: "overrides.txt",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			writeFile(t, path, tt.content)
+
+			got, err := LoadOverrides(path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
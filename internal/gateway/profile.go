@@ -0,0 +1,313 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"mini-reconciliation/internal/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Canonical column names used by profiles that export debits and credits separately.
+const (
+	ColumnDebit  = "debit"
+	ColumnCredit = "credit"
+)
+
+// SignConvention describes how a single amount column encodes the direction of a transaction.
+type SignConvention string
+
+const (
+	// SignDebitNegative means debits are negative and credits positive (the default).
+	SignDebitNegative SignConvention = "debit_negative"
+	// SignDebitPositive means debits are positive and credits negative.
+	SignDebitPositive SignConvention = "debit_positive"
+)
+
+const defaultDateLayout = "2006-01-02"
+
+// BankProfile describes the CSV dialect of one bank's statement export.
+type BankProfile struct {
+	Name               string         `json:"name" yaml:"name"`
+	FilePattern        string         `json:"file_pattern" yaml:"file_pattern"` // glob matched against the file name, e.g. "statement_bank_A*.csv"
+	Delimiter          string         `json:"delimiter" yaml:"delimiter"`
+	DateLayout         string         `json:"date_layout" yaml:"date_layout"` // Go time layout, e.g. "02/01/2006"
+	DecimalSeparator   string         `json:"decimal_separator" yaml:"decimal_separator"`
+	ThousandsSeparator string         `json:"thousands_separator" yaml:"thousands_separator"`
+	DebitCreditColumns bool           `json:"debit_credit_columns" yaml:"debit_credit_columns"` // amounts split across "debit" and "credit" columns
+	SignConvention     SignConvention `json:"sign_convention" yaml:"sign_convention"`
+	Currency           string         `json:"currency" yaml:"currency"`       // used when the statement has no currency column
+	FeeFixed           domain.Decimal `json:"fee_fixed" yaml:"fee_fixed"`     // transfer fee the bank deducts per transaction
+	FeePercent         domain.Decimal `json:"fee_percent" yaml:"fee_percent"` // transfer fee as a percentage of the amount
+	Columns            ColumnMapping  `json:"columns" yaml:"columns"`
+	// ReferencePatterns are regular expressions extracting system transaction IDs from the
+	// description and reference columns, e.g. `REF\s+(SYS\d+)`. The first capturing group
+	// (or the whole match) is the ID.
+	ReferencePatterns []string `json:"reference_patterns" yaml:"reference_patterns"`
+	// ReferenceColumns are headers of extra columns that may hold a system transaction ID.
+	ReferenceColumns []string `json:"reference_columns" yaml:"reference_columns"`
+	// OpeningBalanceLabel and ClosingBalanceLabel mark the rows, by their description, that
+	// carry the statement balances in the amount column, e.g. "Opening Balance".
+	OpeningBalanceLabel string `json:"opening_balance_label" yaml:"opening_balance_label"`
+	ClosingBalanceLabel string `json:"closing_balance_label" yaml:"closing_balance_label"`
+}
+
+// balanceKind tells statement transactions apart from balance rows.
+type balanceKind int
+
+const (
+	noBalance balanceKind = iota
+	openingBalance
+	closingBalance
+)
+
+// ProfileSet is the collection of bank profiles loaded from a config file.
+type ProfileSet struct {
+	Profiles []BankProfile `json:"profiles" yaml:"profiles"`
+}
+
+// HasBalanceRows reports whether the bank's statements carry balance rows.
+func (p BankProfile) HasBalanceRows() bool {
+	return p.OpeningBalanceLabel != "" || p.ClosingBalanceLabel != ""
+}
+
+// HasFee reports whether the bank deducts a transfer fee.
+func (p BankProfile) HasFee() bool {
+	return !p.FeeFixed.IsZero() || !p.FeePercent.IsZero()
+}
+
+// LoadProfiles reads bank profiles from a YAML (.yaml, .yml) or JSON (.json) file.
+func LoadProfiles(path string) (*ProfileSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile file %s: %w", path, err)
+	}
+
+	var set ProfileSet
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &set)
+	case ".json":
+		err = json.Unmarshal(data, &set)
+	default:
+		return nil, fmt.Errorf("unsupported profile file format %s: expected .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse profile file %s: %w", path, err)
+	}
+
+	if err := set.validate(); err != nil {
+		return nil, fmt.Errorf("invalid profile file %s: %w", path, err)
+	}
+	return &set, nil
+}
+
+// Lookup returns the profile with the given name.
+func (s *ProfileSet) Lookup(name string) (BankProfile, bool) {
+	if s == nil {
+		return BankProfile{}, false
+	}
+	for _, p := range s.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return BankProfile{}, false
+}
+
+// Match returns the first profile whose file pattern matches the base name of path.
+func (s *ProfileSet) Match(path string) (BankProfile, bool) {
+	if s == nil {
+		return BankProfile{}, false
+	}
+	base := filepath.Base(path)
+	for _, p := range s.Profiles {
+		if p.FilePattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(p.FilePattern, base); ok {
+			return p, true
+		}
+	}
+	return BankProfile{}, false
+}
+
+func (s *ProfileSet) validate() error {
+	seen := make(map[string]bool, len(s.Profiles))
+	for i, p := range s.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("profile #%d has no name", i+1)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate profile name %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		if p.FilePattern != "" {
+			if _, err := filepath.Match(p.FilePattern, ""); err != nil {
+				return fmt.Errorf("profile %q: invalid file_pattern %q: %w", p.Name, p.FilePattern, err)
+			}
+		}
+		if p.Delimiter != "" && utf8.RuneCountInString(p.Delimiter) != 1 {
+			return fmt.Errorf("profile %q: delimiter must be a single character, got %q", p.Name, p.Delimiter)
+		}
+		if len(p.DecimalSeparator) > 1 {
+			return fmt.Errorf("profile %q: decimal_separator must be a single character, got %q", p.Name, p.DecimalSeparator)
+		}
+		if p.DecimalSeparator != "" && p.DecimalSeparator == p.ThousandsSeparator {
+			return fmt.Errorf("profile %q: decimal_separator and thousands_separator must differ", p.Name)
+		}
+		switch p.SignConvention {
+		case "", SignDebitNegative, SignDebitPositive:
+		default:
+			return fmt.Errorf("profile %q: unknown sign_convention %q", p.Name, p.SignConvention)
+		}
+		if p.OpeningBalanceLabel != "" && strings.EqualFold(strings.TrimSpace(p.OpeningBalanceLabel), strings.TrimSpace(p.ClosingBalanceLabel)) {
+			return fmt.Errorf("profile %q: opening_balance_label and closing_balance_label must differ", p.Name)
+		}
+		for _, pattern := range p.ReferencePatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("profile %q: invalid reference pattern %q: %w", p.Name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p BankProfile) delimiter() rune {
+	if p.Delimiter == "" {
+		return ','
+	}
+	r, _ := utf8.DecodeRuneInString(p.Delimiter)
+	return r
+}
+
+func (p BankProfile) dateLayout() string {
+	if p.DateLayout == "" {
+		return defaultDateLayout
+	}
+	return p.DateLayout
+}
+
+// currency returns the currency of a record, preferring the value of its currency column.
+func (p BankProfile) currency(value string) domain.Currency {
+	if strings.TrimSpace(value) == "" {
+		value = p.Currency
+	}
+	return domain.ParseCurrency(value)
+}
+
+func (p BankProfile) requiredColumns() []string {
+	if p.DebitCreditColumns {
+		return []string{ColumnUniqueIdentifier, ColumnDebit, ColumnCredit, ColumnDate, ColumnDescription}
+	}
+	return bankRequiredColumns
+}
+
+// normalizeNumber rewrites a locale-formatted number (e.g. "1.234,56") into the
+// plain form accepted by strconv ("1234.56"). Parenthesised values are negative.
+func (p BankProfile) normalizeNumber(raw string) string {
+	s := strings.TrimSpace(raw)
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if p.ThousandsSeparator != "" {
+		s = strings.ReplaceAll(s, p.ThousandsSeparator, "")
+	}
+	if p.DecimalSeparator != "" && p.DecimalSeparator != "." {
+		s = strings.ReplaceAll(s, p.DecimalSeparator, ".")
+	}
+	if negative {
+		s = "-" + s
+	}
+	return s
+}
+
+// parseAmount returns the signed statement amount of a record (negative for debits), in
+// the given currency.
+func (p BankProfile) parseAmount(record []string, cols columnIndex, currency domain.Currency) (domain.Decimal, error) {
+	if p.DebitCreditColumns {
+		debit, hasDebit, err := p.parseOptionalNumber(record[cols[ColumnDebit]], currency)
+		if err != nil {
+			return domain.Decimal{}, &fieldError{column: ColumnDebit, value: record[cols[ColumnDebit]], err: err}
+		}
+		credit, hasCredit, err := p.parseOptionalNumber(record[cols[ColumnCredit]], currency)
+		if err != nil {
+			return domain.Decimal{}, &fieldError{column: ColumnCredit, value: record[cols[ColumnCredit]], err: err}
+		}
+		// A row is either a debit or a credit, never neither nor both
+		if !hasDebit && !hasCredit {
+			return domain.Decimal{}, &fieldError{column: ColumnDebit, value: record[cols[ColumnDebit]], err: errNoAmount}
+		}
+		if !debit.IsZero() && !credit.IsZero() {
+			return domain.Decimal{}, &fieldError{column: ColumnCredit, value: record[cols[ColumnCredit]], err: errDebitAndCredit}
+		}
+		return credit.Sub(debit), nil
+	}
+
+	raw := record[cols[ColumnAmount]]
+	amount, err := currency.ParseAmount(p.normalizeNumber(raw))
+	if err != nil {
+		return domain.Decimal{}, &fieldError{column: ColumnAmount, value: raw, err: err}
+	}
+	if p.SignConvention == SignDebitPositive {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+// balanceRow reports whether a row with the given description is a balance row.
+func (p BankProfile) balanceRow(description string) balanceKind {
+	description = strings.TrimSpace(description)
+	switch {
+	case p.OpeningBalanceLabel != "" && strings.EqualFold(description, strings.TrimSpace(p.OpeningBalanceLabel)):
+		return openingBalance
+	case p.ClosingBalanceLabel != "" && strings.EqualFold(description, strings.TrimSpace(p.ClosingBalanceLabel)):
+		return closingBalance
+	}
+	return noBalance
+}
+
+// parseBalance returns the balance held by a balance row. Unlike transaction amounts, a
+// balance keeps its sign whatever the sign convention: negative means overdrawn.
+func (p BankProfile) parseBalance(record []string, cols columnIndex) (domain.Decimal, error) {
+	currency := p.currency(cols.value(record, ColumnCurrency))
+	if p.DebitCreditColumns {
+		return p.parseAmount(record, cols, currency)
+	}
+	raw := record[cols[ColumnAmount]]
+	balance, err := currency.ParseAmount(p.normalizeNumber(raw))
+	if err != nil {
+		return domain.Decimal{}, &fieldError{column: ColumnAmount, value: raw, err: err}
+	}
+	return balance, nil
+}
+
+// Errors of debit and credit columns that do not hold exactly one amount.
+var (
+	errNoAmount       = errors.New("neither debit nor credit holds an amount")
+	errDebitAndCredit = errors.New("both debit and credit hold an amount")
+)
+
+// parseOptionalNumber parses a debit or credit column, reporting whether it holds an
+// amount at all: an empty column or a lone "-" does not.
+func (p BankProfile) parseOptionalNumber(raw string, currency domain.Currency) (domain.Decimal, bool, error) {
+	s := p.normalizeNumber(raw)
+	if s == "" || s == "-" {
+		return domain.Decimal{}, false, nil
+	}
+	v, err := currency.ParseAmount(s)
+	if err != nil {
+		return domain.Decimal{}, false, err
+	}
+	return v.Abs(), true, nil
+}
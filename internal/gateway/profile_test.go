@@ -0,0 +1,249 @@
+package gateway
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		check    func(t *testing.T, p BankProfile)
+		wantErr  bool
+	}{
+		{
+			name:     "valid yaml",
+			filename: "profiles.yaml",
+			content: `profiles:
+  - name: bank_c
+    file_pattern: "bank_c_*.csv"
+    delimiter: ";"
+    date_layout: "02.01.2006"
+    decimal_separator: ","
+    thousands_separator: "."
+    fee_fixed: 2500
+    fee_percent: "0.1"
+    reference_patterns:
+      - 'REF\s+(\w+)'
+    reference_columns: [Kundenreferenz]
+    columns:
+      amount: Betrag
+`,
+			check: func(t *testing.T, p BankProfile) {
+				assert.Equal(t, ';', p.delimiter())
+				assert.Equal(t, "Betrag", p.Columns[ColumnAmount])
+				assert.Equal(t, domain.MustParseDecimal("2500"), p.FeeFixed)
+				assert.Equal(t, domain.MustParseDecimal("0.1"), p.FeePercent)
+				assert.True(t, p.HasFee())
+				assert.Equal(t, []string{`REF\s+(\w+)`}, p.ReferencePatterns)
+				assert.Equal(t, []string{"Kundenreferenz"}, p.ReferenceColumns)
+			},
+		},
+		{
+			name:     "valid json",
+			filename: "profiles.json",
+			content:  `{"profiles": [{"name": "bank_d", "sign_convention": "debit_positive"}]}`,
+		},
+		{
+			name:     "duplicate names",
+			filename: "profiles.yaml",
+			content:  "profiles:\n  - name: a\n  - name: a\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown sign convention",
+			filename: "profiles.json",
+			content:  `{"profiles": [{"name": "a", "sign_convention": "sideways"}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "multi character delimiter",
+			filename: "profiles.json",
+			content:  `{"profiles": [{"name": "a", "delimiter": ";;"}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid reference pattern",
+			filename: "profiles.json",
+			content:  `{"profiles": [{"name": "a", "reference_patterns": ["REF (SYS"]}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported extension",
+			filename: "profiles.toml",
+			content:  "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write profile file: %v", err)
+			}
+
+			got, err := LoadProfiles(path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, got.Profiles, 1)
+			}
+			if tt.check != nil {
+				tt.check(t, got.Profiles[0])
+			}
+		})
+	}
+}
+
+func TestCSVTransactionRepository_GetBankTransactions_Profiles(t *testing.T) {
+	profiles := &ProfileSet{Profiles: []BankProfile{
+		{
+			Name:               "euro",
+			FilePattern:        "euro_*.csv",
+			Delimiter:          ";",
+			DateLayout:         "02.01.2006",
+			DecimalSeparator:   ",",
+			ThousandsSeparator: ".",
+			DebitCreditColumns: true,
+			Columns: ColumnMapping{
+				ColumnDate:             "Buchungsdatum",
+				ColumnUniqueIdentifier: "Referenz",
+				ColumnDescription:      "Verwendungszweck",
+				ColumnDebit:            "Soll",
+				ColumnCredit:           "Haben",
+			},
+		},
+		{
+			Name:           "inverted",
+			SignConvention: SignDebitPositive,
+			Currency:       "usd",
+		},
+	}}
+
+	dir := t.TempDir()
+	euro := filepath.Join(dir, "euro_september.csv")
+	inverted := filepath.Join(dir, "statement_x.csv")
+	writeFile(t, euro, "Buchungsdatum;Referenz;Verwendungszweck;Soll;Haben\n"+
+		"01.09.2025;EU_1;Zahlung trxID:SYS001;1.500,25;\n"+
+		"02.09.2025;EU_2;Eingang;;200,50\n")
+	writeFile(t, inverted, "unique_identifier,amount,date,description\n"+
+		"INV_1,75.00,2025-09-02,ATM\n")
+
+	repo := NewCSVTransactionRepository(
+		WithProfiles(profiles),
+		WithProfileAssignment(inverted, "inverted"),
+	)
+	got, err := repo.GetBankTransactions(context.Background(), domain.FileSources([]string{euro, inverted}))
+	assert.NoError(t, err)
+	if !assert.Len(t, got, 3) {
+		return
+	}
+
+	expected := []domain.BankTransaction{
+		{
+			UniqueIdentifier: "EU_1",
+			Amount:           domain.MustParseDecimal("-1500.25"),
+			Date:             mustParseDate("2025-09-01"),
+			Description:      "Zahlung trxID:SYS001",
+			BankSource:       "euro_september.csv",
+			Type:             domain.TransactionTypeDebit,
+			NormalizedAmount: domain.MustParseDecimal("1500.25"),
+		},
+		{
+			UniqueIdentifier: "EU_2",
+			Amount:           domain.MustParseDecimal("200.50"),
+			Date:             mustParseDate("2025-09-02"),
+			Description:      "Eingang",
+			BankSource:       "euro_september.csv",
+			Type:             domain.TransactionTypeCredit,
+			NormalizedAmount: domain.MustParseDecimal("200.50"),
+		},
+		{
+			UniqueIdentifier: "INV_1",
+			Amount:           domain.MustParseDecimal("-75.00"),
+			Date:             mustParseDate("2025-09-02"),
+			Description:      "ATM",
+			BankSource:       "statement_x.csv",
+			Type:             domain.TransactionTypeDebit,
+			NormalizedAmount: domain.MustParseDecimal("75.00"),
+		},
+	}
+	for i := range expected {
+		assert.True(t, compareBankTransactions(got[i], expected[i]), "transaction[%d] = %+v, want %+v", i, got[i], expected[i])
+	}
+	assert.Equal(t, domain.DefaultCurrency, got[0].Currency)
+	assert.Equal(t, domain.Currency("USD"), got[2].Currency)
+
+	t.Run("debit and credit both empty or both set", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "euro_october.csv")
+		writeFile(t, bad, "Buchungsdatum;Referenz;Verwendungszweck;Soll;Haben\n"+
+			"01.10.2025;EU_3;Leer;;\n"+
+			"02.10.2025;EU_4;Strich;-;-\n"+
+			"03.10.2025;EU_5;Beides;10,00;20,00\n"+
+			"04.10.2025;EU_6;Eingang;0,00;20,00\n")
+		repo := NewCSVTransactionRepository(WithProfiles(profiles), WithLenientParsing(0))
+		got, err := repo.GetBankTransactions(context.Background(), domain.FileSources([]string{bad}))
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, "EU_6", got[0].UniqueIdentifier)
+		}
+		assert.Equal(t, []domain.IngestionError{
+			{File: bad, Line: 2, Column: ColumnDebit, Value: "", Reason: "neither debit nor credit holds an amount"},
+			{File: bad, Line: 3, Column: ColumnDebit, Value: "-", Reason: "neither debit nor credit holds an amount"},
+			{File: bad, Line: 4, Column: ColumnCredit, Value: "20,00", Reason: "both debit and credit hold an amount"},
+		}, repo.IngestionErrors())
+	})
+
+	t.Run("unknown assigned profile", func(t *testing.T) {
+		repo := NewCSVTransactionRepository(
+			WithProfiles(profiles),
+			WithProfileAssignment(inverted, "missing"),
+		)
+		_, err := repo.GetBankTransactions(context.Background(), domain.FileSources([]string{inverted}))
+		assert.ErrorContains(t, err, `unknown bank profile "missing"`)
+	})
+}
+
+func TestCSVTransactionRepository_GetBankTransactions_ReferenceColumns(t *testing.T) {
+	profiles := &ProfileSet{Profiles: []BankProfile{
+		{Name: "refs", FilePattern: "refs_*.csv", ReferenceColumns: []string{"Customer Ref", "Memo"}},
+	}}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "refs_september.csv")
+	writeFile(t, path, "unique_identifier,amount,date,description,customer ref,memo\n"+
+		"R_1,-10.00,2025-09-01,TRANSFER,SYS001,\n")
+
+	repo := NewCSVTransactionRepository(WithProfiles(profiles))
+	got, err := repo.GetBankTransactions(context.Background(), domain.FileSources([]string{path}))
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, []string{"SYS001", ""}, got[0].ReferenceFields)
+	}
+
+	t.Run("missing reference column", func(t *testing.T) {
+		missing := filepath.Join(dir, "refs_october.csv")
+		writeFile(t, missing, "unique_identifier,amount,date,description\n"+
+			"R_2,-10.00,2025-10-01,TRANSFER\n")
+		_, err := repo.GetBankTransactions(context.Background(), domain.FileSources([]string{missing}))
+		assert.ErrorContains(t, err, `missing required column "Customer Ref"`)
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
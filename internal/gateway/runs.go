@@ -0,0 +1,244 @@
+package gateway
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 database/sql driver
+)
+
+const runSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          TEXT PRIMARY KEY,
+	status      TEXT NOT NULL,
+	start_date  TEXT NOT NULL,
+	end_date    TEXT NOT NULL,
+	parameters  TEXT NOT NULL DEFAULT '{}',
+	error       TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL,
+	started_at  TEXT,
+	finished_at TEXT,
+	report      BLOB
+);
+CREATE INDEX IF NOT EXISTS runs_created_at ON runs (created_at);
+CREATE TABLE IF NOT EXISTS run_inputs (
+	run_id   TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	kind     TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	size     INTEGER NOT NULL,
+	sha256   TEXT NOT NULL,
+	PRIMARY KEY (run_id, position)
+);
+`
+
+// SQLiteRunStore keeps the history of reconciliation runs, with their inputs' checksums,
+// parameters and reports, in a local SQLite database.
+type SQLiteRunStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteRunStore opens the SQLite database at path, creating it and its tables if needed.
+func OpenSQLiteRunStore(path string) (*SQLiteRunStore, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run database %s: %w", path, err)
+	}
+	// A single connection serializes writes, which SQLite cannot run concurrently anyway
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(runSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize run database %s: %w", path, err)
+	}
+	return &SQLiteRunStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLiteRunStore) Close() error {
+	return s.db.Close()
+}
+
+// CreateRun stores a new run with its parameters and inputs.
+func (s *SQLiteRunStore) CreateRun(ctx context.Context, run domain.Run) error {
+	params, err := json.Marshal(run.Parameters)
+	if err != nil {
+		return fmt.Errorf("could not encode parameters of run %s: %w", run.ID, err)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO runs (id, status, start_date, end_date, parameters, error, created_at, started_at, finished_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.Status, run.Parameters.Start, run.Parameters.End, string(params), run.Error,
+		formatTime(&run.CreatedAt), formatTime(run.StartedAt), formatTime(run.FinishedAt))
+	if err != nil {
+		return fmt.Errorf("could not insert run %s: %w", run.ID, err)
+	}
+	for i, input := range run.Inputs {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO run_inputs (run_id, position, kind, name, size, sha256) VALUES (?, ?, ?, ?, ?, ?)`,
+			run.ID, i, input.Kind, input.Name, input.Size, input.SHA256)
+		if err != nil {
+			return fmt.Errorf("could not insert input %s of run %s: %w", input.Name, run.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// UpdateRun stores the status, error and timestamps of a run, along with its report once
+// it succeeded.
+func (s *SQLiteRunStore) UpdateRun(ctx context.Context, run domain.Run, report *domain.ReconciliationReport) error {
+	var data []byte
+	if report != nil {
+		var err error
+		if data, err = json.Marshal(report); err != nil {
+			return fmt.Errorf("could not encode report of run %s: %w", run.ID, err)
+		}
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE runs SET status = ?, error = ?, started_at = ?, finished_at = ?, report = ? WHERE id = ?`,
+		run.Status, run.Error, formatTime(run.StartedAt), formatTime(run.FinishedAt), data, run.ID)
+	if err != nil {
+		return fmt.Errorf("could not update run %s: %w", run.ID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("could not update run %s: %w", run.ID, domain.ErrRunNotFound)
+	}
+	return nil
+}
+
+// GetRun returns the run with the given ID, or domain.ErrRunNotFound.
+func (s *SQLiteRunStore) GetRun(ctx context.Context, id string) (domain.Run, error) {
+	runs, err := s.queryRuns(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return domain.Run{}, err
+	}
+	if len(runs) == 0 {
+		return domain.Run{}, domain.ErrRunNotFound
+	}
+	return runs[0], nil
+}
+
+// ListRuns returns the most recently created runs first, at most limit of them (all of
+// them when limit is zero).
+func (s *SQLiteRunStore) ListRuns(ctx context.Context, limit int) ([]domain.Run, error) {
+	if limit <= 0 {
+		limit = -1 // no limit in SQLite
+	}
+	return s.queryRuns(ctx, `ORDER BY created_at DESC, id LIMIT ?`, limit)
+}
+
+// GetReport returns the report of a succeeded run, or domain.ErrRunNotFound.
+func (s *SQLiteRunStore) GetReport(ctx context.Context, id string) (*domain.ReconciliationReport, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT report FROM runs WHERE id = ? AND report IS NOT NULL`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read report of run %s: %w", id, err)
+	}
+
+	var report domain.ReconciliationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("could not decode report of run %s: %w", id, err)
+	}
+	return &report, nil
+}
+
+// queryRuns returns the runs selected by the clause, with their inputs.
+func (s *SQLiteRunStore) queryRuns(ctx context.Context, clause string, args ...any) ([]domain.Run, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, status, start_date, end_date, parameters, error, created_at, started_at, finished_at FROM runs `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]domain.Run, 0)
+	for rows.Next() {
+		var run domain.Run
+		var start, end, params, created string
+		var started, finished sql.NullString
+		if err := rows.Scan(&run.ID, &run.Status, &start, &end, &params, &run.Error, &created, &started, &finished); err != nil {
+			return nil, fmt.Errorf("could not read run: %w", err)
+		}
+		if err := json.Unmarshal([]byte(params), &run.Parameters); err != nil {
+			return nil, fmt.Errorf("could not decode parameters of run %s: %w", run.ID, err)
+		}
+		run.Parameters.Start, run.Parameters.End = start, end
+		createdAt, err := parseTime(sql.NullString{String: created, Valid: true})
+		if err != nil {
+			return nil, err
+		}
+		run.CreatedAt = *createdAt
+		if run.StartedAt, err = parseTime(started); err != nil {
+			return nil, err
+		}
+		if run.FinishedAt, err = parseTime(finished); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not query runs: %w", err)
+	}
+	rows.Close()
+
+	for i := range runs {
+		if runs[i].Inputs, err = s.queryInputs(ctx, runs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+func (s *SQLiteRunStore) queryInputs(ctx context.Context, runID string) ([]domain.RunInput, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT kind, name, size, sha256 FROM run_inputs WHERE run_id = ? ORDER BY position`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("could not query inputs of run %s: %w", runID, err)
+	}
+	defer rows.Close()
+
+	inputs := make([]domain.RunInput, 0)
+	for rows.Next() {
+		var input domain.RunInput
+		if err := rows.Scan(&input.Kind, &input.Name, &input.Size, &input.SHA256); err != nil {
+			return nil, fmt.Errorf("could not read input of run %s: %w", runID, err)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, rows.Err()
+}
+
+// runTimeLayout is RFC 3339 in UTC with a fixed number of fractional digits, so that
+// stored timestamps sort chronologically as text.
+const runTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+func formatTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(runTimeLayout)
+}
+
+func parseTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s.String)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q in run database: %w", s.String, err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteRunStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "runs.db")
+	store, err := OpenSQLiteRunStore(path)
+	require.NoError(t, err)
+
+	created := time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)
+	first := domain.Run{
+		ID:     "run-1",
+		Status: domain.RunQueued,
+		Parameters: domain.RunParameters{
+			Start: "2025-09-01", End: "2025-09-30", ReportingCurrency: "IDR", SettlementLagDays: 2, BusinessDays: true,
+			ToleranceAbsolute: decimalPtr("0.5"), TolerancePercent: decimalPtr("0"), Passes: []string{"reference", "exact", "tolerance"},
+		},
+		Inputs: []domain.RunInput{
+			{Kind: "system", Name: "system.csv", Size: 120, SHA256: "aa"},
+			{Kind: "bank", Name: "bank_a.csv", Size: 80, SHA256: "bb"},
+		},
+		CreatedAt: created,
+	}
+	second := domain.Run{ID: "run-2", Status: domain.RunQueued, Inputs: []domain.RunInput{}, CreatedAt: created.Add(time.Minute)}
+	require.NoError(t, store.CreateRun(ctx, first))
+	require.NoError(t, store.CreateRun(ctx, second))
+
+	got, err := store.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, first, got)
+	_, err = store.GetReport(ctx, "run-1")
+	assert.ErrorIs(t, err, domain.ErrRunNotFound)
+
+	// A succeeded run keeps its report
+	started, finished := created.Add(time.Second), created.Add(2*time.Second)
+	first.Status, first.StartedAt, first.FinishedAt = domain.RunSucceeded, &started, &finished
+	report := &domain.ReconciliationReport{
+		ReconciliationSummary:  domain.Summary{TimeframeStart: "2025-09-01", TimeframeEnd: "2025-09-30"},
+		DiscrepantTransactions: domain.DiscrepantTransactions{Count: 1, TotalDiscrepancyValue: domain.MustParseDecimal("12.50")},
+		UnmatchedTransactions: domain.UnmatchedTransactions{
+			Count:                 1,
+			SystemMissingFromBank: []domain.SystemTransaction{{TrxID: "SYS001", Amount: domain.MustParseDecimal("10"), Type: domain.TransactionTypeDebit, TransactionTime: created}},
+		},
+	}
+	require.NoError(t, store.UpdateRun(ctx, first, report))
+	require.NoError(t, store.Close())
+
+	// The history survives reopening the database
+	store, err = OpenSQLiteRunStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	got, err = store.GetRun(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, first, got)
+	gotReport, err := store.GetReport(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, report, gotReport)
+
+	runs, err := store.ListRuns(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "run-2", runs[0].ID)
+	runs, err = store.ListRuns(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+
+	_, err = store.GetRun(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrRunNotFound)
+	assert.ErrorIs(t, store.UpdateRun(ctx, domain.Run{ID: "missing"}, nil), domain.ErrRunNotFound)
+}
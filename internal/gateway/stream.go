@@ -0,0 +1,315 @@
+package gateway
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// DefaultSortBuffer is how many transactions a stream sorts in memory before spilling them
+// to a temporary file, unless WithSortBuffer is given.
+const DefaultSortBuffer = 100000
+
+// WithSortBuffer sets how many transactions a stream sorts in memory. Larger inputs are
+// sorted in runs of that many transactions written to temporary files, then merged.
+func WithSortBuffer(rows int) Option {
+	return func(r *CSVTransactionRepository) {
+		r.sortBuffer = rows
+	}
+}
+
+// StreamSystemTransactions reads the system transactions source row by row and returns
+// those dated from start to the end of end's day, in order of date and time. Only
+// WithSortBuffer transactions are held in memory at once; the rest wait in temporary files
+// that are removed when the stream is closed.
+func (r *CSVTransactionRepository) StreamSystemTransactions(ctx context.Context, source domain.Source, start, end time.Time) (domain.SystemTransactionStream, error) {
+	sorter := newRunSorter(r.sortBuffer, systemTimeLess, jsonCodec[domain.SystemTransaction]())
+	err := r.scanSystem(source, r.onRowError(), func(tx domain.SystemTransaction) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !inTimeframe(tx.TransactionTime, start, end) {
+			return nil
+		}
+		return sorter.add(tx)
+	})
+	if err != nil {
+		sorter.discard()
+		return nil, err
+	}
+	return sorter.stream()
+}
+
+// StreamBankTransactions reads the bank statement sources row by row and returns the
+// transactions dated from start to the end of end's day, in order of date. Like
+// StreamSystemTransactions, it holds at most WithSortBuffer transactions in memory.
+func (r *CSVTransactionRepository) StreamBankTransactions(ctx context.Context, sources []domain.Source, start, end time.Time) (domain.BankTransactionStream, error) {
+	sorter := newRunSorter(r.sortBuffer, bankDateLess, bankCodec)
+	for _, source := range sources {
+		err := r.readStatement(source, func(tx domain.BankTransaction) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !inTimeframe(tx.Date, start, end) {
+				return nil
+			}
+			return sorter.add(tx)
+		})
+		if err != nil {
+			sorter.discard()
+			return nil, err
+		}
+	}
+	return sorter.stream()
+}
+
+// inTimeframe reports whether t falls between start and the end of end's day.
+func inTimeframe(t, start, end time.Time) bool {
+	return t.After(start.Add(-time.Nanosecond)) && t.Before(end.Add(24*time.Hour-time.Nanosecond))
+}
+
+// Streams are ordered by the calendar date a transaction is recorded on, which for
+// timestamps in different zones is not always the order of the instants.
+
+func systemTimeLess(a, b domain.SystemTransaction) bool {
+	if da, db := calendarDay(a.TransactionTime), calendarDay(b.TransactionTime); !da.Equal(db) {
+		return da.Before(db)
+	}
+	return a.TransactionTime.Before(b.TransactionTime)
+}
+
+func bankDateLess(a, b domain.BankTransaction) bool {
+	if da, db := calendarDay(a.Date), calendarDay(b.Date); !da.Equal(db) {
+		return da.Before(db)
+	}
+	return a.Date.Before(b.Date)
+}
+
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// runCodec writes values to a sort run and reads them back.
+type runCodec[T any] struct {
+	encode func(*json.Encoder, T) error
+	decode func(*json.Decoder) (T, error)
+}
+
+func jsonCodec[T any]() runCodec[T] {
+	return runCodec[T]{
+		encode: func(enc *json.Encoder, v T) error { return enc.Encode(v) },
+		decode: func(dec *json.Decoder) (T, error) {
+			var v T
+			err := dec.Decode(&v)
+			return v, err
+		},
+	}
+}
+
+// spooledBankTransaction is a bank transaction as written to a sort run, keeping the
+// reference fields its JSON form leaves out.
+type spooledBankTransaction struct {
+	Transaction domain.BankTransaction `json:"transaction"`
+	References  []string               `json:"references,omitempty"`
+}
+
+var bankCodec = runCodec[domain.BankTransaction]{
+	encode: func(enc *json.Encoder, tx domain.BankTransaction) error {
+		return enc.Encode(spooledBankTransaction{Transaction: tx, References: tx.ReferenceFields})
+	},
+	decode: func(dec *json.Decoder) (domain.BankTransaction, error) {
+		var spooled spooledBankTransaction
+		if err := dec.Decode(&spooled); err != nil {
+			return domain.BankTransaction{}, err
+		}
+		tx := spooled.Transaction
+		tx.ReferenceFields = spooled.References
+		normalizeBankTransaction(&tx)
+		return tx, nil
+	},
+}
+
+// runSorter sorts more values than fit in memory. Values are buffered up to a limit; each
+// full buffer is sorted and written to a temporary file as a run, and the runs are merged
+// holding one value per run in memory. Equal values keep the order they were added in.
+type runSorter[T any] struct {
+	limit int
+	less  func(a, b T) bool
+	codec runCodec[T]
+	buf   []T
+	runs  []*os.File
+}
+
+func newRunSorter[T any](limit int, less func(a, b T) bool, codec runCodec[T]) *runSorter[T] {
+	if limit <= 0 {
+		limit = DefaultSortBuffer
+	}
+	return &runSorter[T]{limit: limit, less: less, codec: codec}
+}
+
+func (s *runSorter[T]) add(v T) error {
+	s.buf = append(s.buf, v)
+	if len(s.buf) >= s.limit {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill writes the buffered values to a new run.
+func (s *runSorter[T]) spill() error {
+	s.sortBuffer()
+	file, err := os.CreateTemp("", "reconciliation-run-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to create sort run: %w", err)
+	}
+	s.runs = append(s.runs, file)
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, v := range s.buf {
+		if err := s.codec.encode(enc, v); err != nil {
+			return fmt.Errorf("failed to write sort run %s: %w", file.Name(), err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write sort run %s: %w", file.Name(), err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind sort run %s: %w", file.Name(), err)
+	}
+	clear(s.buf)
+	s.buf = s.buf[:0]
+	return nil
+}
+
+func (s *runSorter[T]) sortBuffer() {
+	sort.SliceStable(s.buf, func(i, j int) bool { return s.less(s.buf[i], s.buf[j]) })
+}
+
+// stream returns the values added so far in order. Values that fit in the buffer are
+// streamed from memory without touching the disk.
+func (s *runSorter[T]) stream() (domain.Stream[T], error) {
+	if len(s.runs) == 0 {
+		s.sortBuffer()
+		return domain.SliceStream(s.buf), nil
+	}
+	if len(s.buf) > 0 {
+		if err := s.spill(); err != nil {
+			s.discard()
+			return nil, err
+		}
+	}
+
+	m := &mergeStream[T]{codec: s.codec, runs: s.runs, heads: runHeads[T]{less: s.less}}
+	s.buf, s.runs = nil, nil
+	for _, file := range m.runs {
+		m.decoders = append(m.decoders, json.NewDecoder(bufio.NewReader(file)))
+	}
+	for run := range m.runs {
+		if err := m.advance(run); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// discard removes the runs written so far, when sorting is abandoned.
+func (s *runSorter[T]) discard() {
+	for _, file := range s.runs {
+		removeRun(file)
+	}
+	s.buf, s.runs = nil, nil
+}
+
+func removeRun(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// mergeStream merges sorted runs into one sorted stream.
+type mergeStream[T any] struct {
+	codec    runCodec[T]
+	runs     []*os.File
+	decoders []*json.Decoder
+	heads    runHeads[T]
+}
+
+// advance reads the next value of a run into the heads.
+func (m *mergeStream[T]) advance(run int) error {
+	v, err := m.codec.decode(m.decoders[run])
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read sort run %s: %w", m.runs[run].Name(), err)
+	}
+	heap.Push(&m.heads, runHead[T]{value: v, run: run})
+	return nil
+}
+
+func (m *mergeStream[T]) Next() (T, error) {
+	if m.heads.Len() == 0 {
+		var zero T
+		return zero, io.EOF
+	}
+	head := heap.Pop(&m.heads).(runHead[T])
+	if err := m.advance(head.run); err != nil {
+		var zero T
+		return zero, err
+	}
+	return head.value, nil
+}
+
+// Close removes the runs.
+func (m *mergeStream[T]) Close() error {
+	for _, file := range m.runs {
+		removeRun(file)
+	}
+	m.runs, m.decoders, m.heads.items = nil, nil, nil
+	return nil
+}
+
+// runHead is the next value of a run.
+type runHead[T any] struct {
+	value T
+	run   int
+}
+
+// runHeads is a heap of the next value of each run. Equal values come out in run order,
+// which is the order they were added in.
+type runHeads[T any] struct {
+	items []runHead[T]
+	less  func(a, b T) bool
+}
+
+func (h runHeads[T]) Len() int { return len(h.items) }
+
+func (h runHeads[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.value, b.value) {
+		return true
+	}
+	if h.less(b.value, a.value) {
+		return false
+	}
+	return a.run < b.run
+}
+
+func (h runHeads[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *runHeads[T]) Push(x any) { h.items = append(h.items, x.(runHead[T])) }
+
+func (h *runHeads[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
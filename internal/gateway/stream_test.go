@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect[T any](t *testing.T, stream domain.Stream[T]) []T {
+	t.Helper()
+	var values []T
+	for {
+		v, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return values
+		}
+		require.NoError(t, err)
+		values = append(values, v)
+	}
+}
+
+func TestCSVTransactionRepository_StreamSystemTransactions(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)
+	source := domain.BytesSource("system.csv", []byte("trxID,amount,type,transactionTime\n"+
+		"SYS005,5.00,DEBIT,2025-09-03T10:00:00Z\n"+
+		"SYS000,1.00,DEBIT,2025-08-31T23:59:59Z\n"+
+		"SYS002,2.00,CREDIT,2025-09-02T01:00:00+07:00\n"+
+		"SYS003,3.00,DEBIT,2025-09-01T20:00:00Z\n"+
+		"SYS001,1.50,DEBIT,2025-09-01T08:00:00Z\n"+
+		"SYS009,9.00,DEBIT,2025-09-04T00:00:00Z\n"+
+		"SYS004,4.00,CREDIT,2025-09-02T12:00:00Z\n"))
+
+	for _, buffer := range []int{DefaultSortBuffer, 2} {
+		repo := NewCSVTransactionRepository(WithSortBuffer(buffer))
+		stream, err := repo.StreamSystemTransactions(context.Background(), source, start, end)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, tx := range collect(t, stream) {
+			ids = append(ids, tx.TrxID)
+		}
+		// Out of the timeframe rows are dropped; SYS002 is on 2 September where it was recorded
+		assert.Equal(t, []string{"SYS001", "SYS003", "SYS002", "SYS004", "SYS005"}, ids, "buffer %d", buffer)
+
+		require.NoError(t, stream.Close())
+		runs, err := os.ReadDir(tmp)
+		require.NoError(t, err)
+		assert.Empty(t, runs, "sort runs are removed on close")
+	}
+}
+
+func TestCSVTransactionRepository_StreamBankTransactions(t *testing.T) {
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+	profiles := &ProfileSet{Profiles: []BankProfile{{Name: "bank_a", FilePattern: "bank_a*.csv", ReferenceColumns: []string{"Ref"}}}}
+	repo := NewCSVTransactionRepository(WithProfiles(profiles), WithSortBuffer(1))
+
+	stream, err := repo.StreamBankTransactions(context.Background(), []domain.Source{
+		domain.BytesSource("bank_a.csv", []byte("unique_identifier,amount,date,description,Ref\n"+
+			"A2,-20.00,2025-09-02,Fee,SYS002\n"+
+			"A1,10.00,2025-09-01,Deposit,SYS001\n")),
+		domain.BytesSource("bank_b.csv", []byte("unique_identifier,amount,date,description\n"+
+			"B3,30.00,2025-09-03,Late\n"+
+			"B1,-15.00,2025-09-01,Payment\n")),
+	}, start, end)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	// Normalized fields and references survive the sort runs
+	assert.Equal(t, []domain.BankTransaction{
+		{UniqueIdentifier: "A1", Amount: domain.MustParseDecimal("10.00"), Currency: domain.DefaultCurrency, Date: start, Description: "Deposit", BankSource: "bank_a.csv",
+			NormalizedAmount: domain.MustParseDecimal("10.00"), Type: domain.TransactionTypeCredit, ReferenceFields: []string{"SYS001"}},
+		{UniqueIdentifier: "B1", Amount: domain.MustParseDecimal("-15.00"), Currency: domain.DefaultCurrency, Date: start, Description: "Payment", BankSource: "bank_b.csv",
+			NormalizedAmount: domain.MustParseDecimal("15.00"), Type: domain.TransactionTypeDebit},
+		{UniqueIdentifier: "A2", Amount: domain.MustParseDecimal("-20.00"), Currency: domain.DefaultCurrency, Date: end, Description: "Fee", BankSource: "bank_a.csv",
+			NormalizedAmount: domain.MustParseDecimal("20.00"), Type: domain.TransactionTypeDebit, ReferenceFields: []string{"SYS002"}},
+	}, collect(t, stream))
+}
+
+func TestCSVTransactionRepository_StreamErrors(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewCSVTransactionRepository(WithSortBuffer(1))
+
+	_, err := repo.StreamSystemTransactions(context.Background(), domain.BytesSource("system.csv", []byte("trxID,amount,type,transactionTime\n"+
+		"SYS001,1.00,DEBIT,2025-09-01T08:00:00Z\n"+
+		"SYS002,x,DEBIT,2025-09-01T09:00:00Z\n")), day, day)
+	assert.ErrorContains(t, err, "system.csv:3")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = repo.StreamBankTransactions(ctx, []domain.Source{domain.BytesSource("bank.csv", []byte("unique_identifier,amount,date,description\n"+
+		"B1,1.00,2025-09-01,Deposit\n"))}, day, day)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	runs, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, runs, "sort runs are removed when reading fails")
+}
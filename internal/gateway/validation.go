@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"context"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// FileValidation is the outcome of validating one input file.
+type FileValidation struct {
+	File   string                  `json:"file"`
+	Rows   int                     `json:"rows"` // data rows read, valid or not
+	Errors []domain.IngestionError `json:"errors"`
+}
+
+// Valid reports whether the file was read without errors.
+func (v FileValidation) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// ValidateSystemFile reads the system transactions source and reports every row that
+// cannot be read, instead of stopping at the first one.
+func (r *CSVTransactionRepository) ValidateSystemFile(ctx context.Context, source domain.Source) FileValidation {
+	v := FileValidation{File: source.Name, Errors: make([]domain.IngestionError, 0)}
+	err := r.scanSystem(source, v.collect, func(domain.SystemTransaction) error {
+		v.Rows++
+		return nil
+	})
+	if err != nil {
+		v.Errors = append(v.Errors, domain.IngestionError{File: source.Name, Reason: err.Error()})
+	}
+	return v
+}
+
+// ValidateBankFile reads the bank statement source with its profile and reports every row
+// that cannot be read, instead of stopping at the first one.
+func (r *CSVTransactionRepository) ValidateBankFile(ctx context.Context, source domain.Source) FileValidation {
+	v := FileValidation{File: source.Name, Errors: make([]domain.IngestionError, 0)}
+	balance, err := r.scanStatement(source, v.collect, func(domain.BankTransaction) error {
+		v.Rows++
+		return nil
+	})
+	if err != nil {
+		v.Errors = append(v.Errors, domain.IngestionError{File: source.Name, Reason: err.Error()})
+		return v
+	}
+	if balance.Opening != nil {
+		v.Rows++
+	}
+	if balance.Closing != nil {
+		v.Rows++
+	}
+	return v
+}
+
+// collect records a row error and skips the row.
+func (v *FileValidation) collect(rowErr domain.IngestionError) error {
+	v.Rows++
+	v.Errors = append(v.Errors, rowErr)
+	return nil
+}
@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVTransactionRepository_ValidateSystemFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		wantRows int
+		want     []domain.IngestionError
+	}{
+		{
+			name: "valid",
+			content: "trxID,amount,type,transactionTime\n" +
+				"SYS001,150.00,DEBIT,2025-09-01T10:00:00Z\n",
+			wantRows: 1,
+			want:     []domain.IngestionError{},
+		},
+		{
+			name: "every bad row",
+			content: "trxID,amount,type,transactionTime\n" +
+				"SYS001,abc,DEBIT,2025-09-01T10:00:00Z\n" +
+				"SYS002,200.50,CREDIT,2025-09-01T11:30:00Z\n" +
+				"SYS003,75.00,DEBIT\n" +
+				"SYS004,75.00,DEBIT,yesterday\n" +
+				"SYS005,75.00,debit,2025-09-01T12:00:00Z\n" +
+				"SYS006,75.00,FOO,2025-09-01T12:00:00Z\n" +
+				"SYS007,75.00,,2025-09-01T12:00:00Z\n",
+			wantRows: 7,
+			want: []domain.IngestionError{
+				{Line: 2, Column: ColumnAmount, Value: "abc", Reason: `invalid decimal "abc"`},
+				{Line: 4, Reason: "wrong number of fields"},
+				{Line: 5, Column: ColumnTransactionTime, Value: "yesterday", Reason: `parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+				{Line: 6, Column: ColumnType, Value: "debit", Reason: "type must be DEBIT or CREDIT"},
+				{Line: 7, Column: ColumnType, Value: "FOO", Reason: "type must be DEBIT or CREDIT"},
+				{Line: 8, Column: ColumnType, Value: "", Reason: "type must be DEBIT or CREDIT"},
+			},
+		},
+		{
+			name:    "missing column",
+			content: "trxID,amount,type\nSYS001,150.00,DEBIT\n",
+			want: []domain.IngestionError{
+				{Reason: `file %s is missing required column "transactionTime" (header "transactionTime")`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".csv")
+			writeFile(t, path, tt.content)
+			for i := range tt.want {
+				tt.want[i].File = path
+				if tt.want[i].Line == 0 {
+					tt.want[i].Reason = fmt.Sprintf(tt.want[i].Reason, path)
+				}
+			}
+
+			got := NewCSVTransactionRepository().ValidateSystemFile(ctx, domain.FileSource(path))
+			assert.Equal(t, path, got.File)
+			assert.Equal(t, tt.wantRows, got.Rows)
+			assert.Equal(t, tt.want, got.Errors)
+			assert.Equal(t, len(tt.want) == 0, got.Valid())
+		})
+	}
+}
+
+func TestCSVTransactionRepository_ValidateBankFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statement_bank_A.csv")
+	writeFile(t, path, "unique_identifier;date;description;amount\n"+
+		"A0;01.09.2025;Saldo Awal;1.000,00\n"+
+		"A1;01.09.2025;Payment;-150,00\n"+
+		"A2;2025-09-02;Payment;-75,00\n"+
+		"A3;02.09.2025;Payment;x\n"+
+		"A4;02.09.2025;Saldo Akhir;775,00\n")
+	profiles := &ProfileSet{Profiles: []BankProfile{{
+		Name: "bank_a", FilePattern: "statement_bank_A*.csv", Delimiter: ";",
+		DecimalSeparator: ",", ThousandsSeparator: ".", DateLayout: "02.01.2006",
+		OpeningBalanceLabel: "Saldo Awal", ClosingBalanceLabel: "Saldo Akhir",
+	}}}
+
+	got := NewCSVTransactionRepository(WithProfiles(profiles)).ValidateBankFile(context.Background(), domain.FileSource(path))
+	assert.Equal(t, 5, got.Rows)
+	if assert.Len(t, got.Errors, 2) {
+		assert.Equal(t, 4, got.Errors[0].Line)
+		assert.Equal(t, ColumnDate, got.Errors[0].Column)
+		assert.Equal(t, "2025-09-02", got.Errors[0].Value)
+		assert.Equal(t, 5, got.Errors[1].Line)
+		assert.Equal(t, ColumnAmount, got.Errors[1].Column)
+		assert.Equal(t, "x", got.Errors[1].Value)
+	}
+}
+
+func TestIngestionError_Error(t *testing.T) {
+	err := rowError("bank.csv", 7, &fieldError{column: ColumnAmount, value: "1,2,3", err: errors.New("invalid decimal")})
+	assert.EqualError(t, err, "bank.csv:7: could not parse amount '1,2,3': invalid decimal")
+}
@@ -0,0 +1,63 @@
+package presenter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// WriteCSVBundle writes the exceptions of the report to dir, creating it if needed, as
+// discrepancies.csv, system_missing.csv and bank_missing.csv.
+func WriteCSVBundle(dir string, report *domain.ReconciliationReport) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", dir, err)
+	}
+	for _, t := range exceptionTables(report) {
+		if err := writeCSVTable(filepath.Join(dir, t.name+".csv"), t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSVTable(path string, t table) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(t.header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	for _, row := range t.rows {
+		record := make([]string, len(row))
+		for i, c := range row {
+			record[i] = csvValue(c)
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// csvValue returns the value of c as written to a CSV file. Spreadsheets evaluate a cell
+// starting with one of these characters as a formula, so text such as a bank description
+// of "=HYPERLINK(...)" is prefixed with an apostrophe to be shown as written. Numbers,
+// which may start with a minus sign, are written as they are.
+func csvValue(c cell) string {
+	if !c.numeric && c.value != "" && strings.ContainsRune("=+-@\t\r", rune(c.value[0])) {
+		return "'" + c.value
+	}
+	return c.value
+}
@@ -0,0 +1,53 @@
+package presenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCSVBundle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	assert.NoError(t, WriteCSVBundle(dir, sampleReport()))
+
+	want := map[string]string{
+		"discrepancies.csv": "system_trx_id,system_type,system_date,system_amount,system_currency,bank_unique_identifier,bank_source,bank_date,bank_amount,bank_currency,reason,difference,day_offset\n" +
+			"SYS001,DEBIT,2025-09-01,100,IDR,B1,bank_a.csv,2025-09-01,-97.5,IDR,fee_deducted,2.5,0\n",
+		"system_missing.csv": "trx_id,type,transaction_time,amount,currency\n" +
+			"SYS002,CREDIT,2025-09-01T10:00:00Z,40.25,IDR\n",
+		"bank_missing.csv": "bank_source,unique_identifier,date,amount,currency,description\n" +
+			"bank_a.csv,B2,2025-09-01,-5,IDR,Fee\n" +
+			"bank_b.csv,B9,2025-09-01,10,IDR,\"Refund \"\"x\"\" & <y>\"\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, content, string(got), name)
+		}
+	}
+}
+
+func TestWriteCSVBundle_FormulaText(t *testing.T) {
+	report := sampleReport()
+	report.UnmatchedTransactions.BankMissingFromSystem = map[string][]domain.BankTransaction{
+		"bank_a.csv": {
+			{UniqueIdentifier: "@B3", Amount: domain.MustParseDecimal("-5"), Currency: "IDR", Date: report.DiscrepantTransactions.Details[0].BankTransaction.Date, Description: "=HYPERLINK(\"http://example.com\")", BankSource: "bank_a.csv"},
+			{UniqueIdentifier: "B4", Amount: domain.MustParseDecimal("7"), Currency: "IDR", Date: report.DiscrepantTransactions.Details[0].BankTransaction.Date, Description: "+62 transfer", BankSource: "bank_a.csv"},
+			{UniqueIdentifier: "B5", Amount: domain.MustParseDecimal("8"), Currency: "IDR", Date: report.DiscrepantTransactions.Details[0].BankTransaction.Date, Description: "-fee", BankSource: "bank_a.csv"},
+		},
+	}
+	dir := t.TempDir()
+	assert.NoError(t, WriteCSVBundle(dir, report))
+
+	got, err := os.ReadFile(filepath.Join(dir, "bank_missing.csv"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bank_source,unique_identifier,date,amount,currency,description\n"+
+			"bank_a.csv,'@B3,2025-09-01,-5,IDR,\"'=HYPERLINK(\"\"http://example.com\"\")\"\n"+
+			"bank_a.csv,B4,2025-09-01,7,IDR,'+62 transfer\n"+
+			"bank_a.csv,B5,2025-09-01,8,IDR,'-fee\n", string(got))
+	}
+}
@@ -0,0 +1,46 @@
+// Package presenter renders a reconciliation report in the output formats of the CLI.
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Format is an output format of the reconciliation report.
+type Format string
+
+const (
+	// FormatJSON is the whole report as indented JSON.
+	FormatJSON Format = "json"
+	// FormatCSV is a directory of CSV files, one per exception list.
+	FormatCSV Format = "csv"
+	// FormatXLSX is an Excel workbook with a summary sheet and one sheet per exception list.
+	FormatXLSX Format = "xlsx"
+	// FormatHTML is a self-contained HTML page with sortable tables.
+	FormatHTML Format = "html"
+)
+
+// ParseFormat returns the format with the given name, case-insensitively.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case FormatJSON, FormatCSV, FormatXLSX, FormatHTML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q: expected json, csv, xlsx or html", name)
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, report *domain.ReconciliationReport) error {
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON report: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(output)); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
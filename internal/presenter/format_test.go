@@ -0,0 +1,85 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "json", want: FormatJSON},
+		{name: " CSV ", want: FormatCSV},
+		{name: "Xlsx", want: FormatXLSX},
+		{name: "html", want: FormatHTML},
+		{name: "pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJSON(&buf, sampleReport()))
+
+	var got map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Contains(t, got, "reconciliation_summary")
+	assert.Contains(t, buf.String(), "\n  \"discrepant_transactions\"")
+}
+
+// sampleReport returns a report with one discrepancy and unmatched transactions on both
+// sides, from two bank statements.
+func sampleReport() *domain.ReconciliationReport {
+	day := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	return &domain.ReconciliationReport{
+		ReconciliationSummary: domain.Summary{
+			TimeframeStart:                   "2025-09-01",
+			TimeframeEnd:                     "2025-09-05",
+			ReportingCurrency:                domain.DefaultCurrency,
+			TotalSystemTransactionsProcessed: 3,
+			TotalBankTransactionsProcessed:   4,
+			MatchedTransactions:              1,
+		},
+		DiscrepantTransactions: domain.DiscrepantTransactions{
+			Count:                 1,
+			TotalDiscrepancyValue: domain.MustParseDecimal("2.5"),
+			Details: []domain.DiscrepancyDetail{{
+				SystemTransaction: domain.SystemTransaction{TrxID: "SYS001", Amount: domain.MustParseDecimal("100"), Currency: "IDR", Type: domain.TransactionTypeDebit, TransactionTime: day},
+				BankTransaction:   domain.BankTransaction{UniqueIdentifier: "B1", Amount: domain.MustParseDecimal("-97.5"), Currency: "IDR", Date: day, Description: "Transfer", BankSource: "bank_a.csv"},
+				Reason:            domain.DiscrepancyFeeDeducted,
+				Difference:        domain.MustParseDecimal("2.5"),
+			}},
+		},
+		GroupedMatches: domain.GroupedMatches{Details: []domain.GroupedMatch{}},
+		UnmatchedTransactions: domain.UnmatchedTransactions{
+			Count: 3,
+			SystemMissingFromBank: []domain.SystemTransaction{
+				{TrxID: "SYS002", Amount: domain.MustParseDecimal("40.25"), Currency: "IDR", Type: domain.TransactionTypeCredit, TransactionTime: day},
+			},
+			BankMissingFromSystem: map[string][]domain.BankTransaction{
+				"bank_b.csv": {{UniqueIdentifier: "B9", Amount: domain.MustParseDecimal("10"), Currency: "IDR", Date: day, Description: `Refund "x" & <y>`, BankSource: "bank_b.csv"}},
+				"bank_a.csv": {{UniqueIdentifier: "B2", Amount: domain.MustParseDecimal("-5"), Currency: "IDR", Date: day, Description: "Fee", BankSource: "bank_a.csv"}},
+			},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package presenter
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"mini-reconciliation/internal/domain"
+)
+
+//go:embed html_report.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// htmlPage is the data of the HTML report template.
+type htmlPage struct {
+	Start, End    string
+	Summary       htmlTable
+	Discrepancies htmlTable
+	SystemMissing htmlTable
+	BankMissing   []htmlTable // one per bank statement
+	Totals        htmlTable
+}
+
+type htmlTable struct {
+	Title    string
+	Sortable bool
+	Header   []string
+	Rows     [][]htmlCell
+}
+
+type htmlCell struct {
+	Value   string
+	Numeric bool
+}
+
+// WriteHTML writes the report as a single self-contained HTML page, with styles and the
+// script sorting its tables inline, so it can be mailed and opened offline.
+func WriteHTML(w io.Writer, report *domain.ReconciliationReport) error {
+	exceptions := exceptionTables(report)
+	page := htmlPage{
+		Start:         report.ReconciliationSummary.TimeframeStart,
+		End:           report.ReconciliationSummary.TimeframeEnd,
+		Summary:       newHTMLTable(summaryTable(report), false),
+		Discrepancies: newHTMLTable(exceptions[0], true),
+		SystemMissing: newHTMLTable(exceptions[1], true),
+		Totals:        newHTMLTable(unmatchedTotalsTable(report), true),
+	}
+	for _, source := range bankSources(report) {
+		page.BankMissing = append(page.BankMissing, newHTMLTable(table{
+			title:  source,
+			header: []string{"unique_identifier", "date", "amount", "currency", "description"},
+			rows:   bankRows(report.UnmatchedTransactions.BankMissingFromSystem[source]),
+		}, true))
+	}
+
+	if err := htmlReport.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+func newHTMLTable(t table, sortable bool) htmlTable {
+	h := htmlTable{Title: t.title, Sortable: sortable, Header: t.header}
+	for _, row := range t.rows {
+		cells := make([]htmlCell, len(row))
+		for i, c := range row {
+			cells[i] = htmlCell{Value: c.value, Numeric: c.numeric}
+		}
+		h.Rows = append(h.Rows, cells)
+	}
+	return h
+}
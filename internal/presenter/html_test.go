@@ -0,0 +1,36 @@
+package presenter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteHTML(&buf, sampleReport()))
+	got := buf.String()
+
+	assert.Contains(t, got, "<title>Reconciliation 2025-09-01 to 2025-09-05</title>")
+	// Self-contained: no external stylesheets, scripts or images
+	assert.NotContains(t, got, " src=")
+	assert.NotContains(t, got, "<link")
+	assert.Contains(t, got, `<td>total_discrepancy_value</td><td class="num">2.5</td>`)
+	assert.Contains(t, got, `<td>SYS001</td><td>DEBIT</td><td>2025-09-01</td><td class="num">100</td>`)
+	// One table per bank statement, in name order, with descriptions escaped
+	assert.Less(t, strings.Index(got, "<h3>bank_a.csv</h3>"), strings.Index(got, "<h3>bank_b.csv</h3>"))
+	assert.Contains(t, got, "<td>Refund &#34;x&#34; &amp; &lt;y&gt;</td>")
+	// Unmatched totals: bank debits are typed by their sign and totalled unsigned
+	assert.Contains(t, got, `<td>2025-09-01</td><td>CREDIT</td><td>IDR</td><td class="num">1</td><td class="num">40.25</td><td class="num">1</td><td class="num">10</td>`)
+	assert.Contains(t, got, `<td>2025-09-01</td><td>DEBIT</td><td>IDR</td><td class="num">0</td><td class="num">0</td><td class="num">1</td><td class="num">5</td>`)
+}
+
+func TestWriteHTML_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteHTML(&buf, &domain.ReconciliationReport{}))
+	assert.Equal(t, 4, strings.Count(buf.String(), `<p class="empty">None</p>`))
+}
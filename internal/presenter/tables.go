@@ -0,0 +1,190 @@
+package presenter
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// cell is one value of a table, kept as text. Numeric cells hold plain decimal numbers
+// that spreadsheet formats store as numbers.
+type cell struct {
+	value   string
+	numeric bool
+}
+
+func text(value string) cell { return cell{value: value} }
+
+func number(value domain.Decimal) cell { return cell{value: value.String(), numeric: true} }
+
+func count(value int) cell { return cell{value: strconv.Itoa(value), numeric: true} }
+
+// table is a section of the report laid out as rows, shared by the tabular formats.
+type table struct {
+	name   string // file name without extension, e.g. "system_missing"
+	title  string // sheet name, e.g. "System Missing"
+	header []string
+	rows   [][]cell
+}
+
+// summaryTable lists the figures of the reconciliation summary, one per row.
+func summaryTable(report *domain.ReconciliationReport) table {
+	s := report.ReconciliationSummary
+	unmatched := report.UnmatchedTransactions
+	return table{
+		name:   "summary",
+		title:  "Summary",
+		header: []string{"item", "value"},
+		rows: [][]cell{
+			{text("timeframe_start"), text(s.TimeframeStart)},
+			{text("timeframe_end"), text(s.TimeframeEnd)},
+			{text("reporting_currency"), text(string(s.ReportingCurrency))},
+			{text("total_system_transactions_processed"), count(s.TotalSystemTransactionsProcessed)},
+			{text("total_bank_transactions_processed"), count(s.TotalBankTransactionsProcessed)},
+			{text("matched_transactions"), count(s.MatchedTransactions)},
+			{text("discrepant_transactions"), count(report.DiscrepantTransactions.Count)},
+			{text("total_discrepancy_value"), number(report.DiscrepantTransactions.TotalDiscrepancyValue)},
+			{text("grouped_matches"), count(report.GroupedMatches.Count)},
+			{text("grouped_system_transactions"), count(s.GroupedSystemTransactions)},
+			{text("grouped_bank_transactions"), count(s.GroupedBankTransactions)},
+			{text("unmatched_transactions"), count(unmatched.Count)},
+			{text("system_missing_from_bank"), count(len(unmatched.SystemMissingFromBank))},
+			{text("bank_missing_from_system"), count(unmatched.Count - len(unmatched.SystemMissingFromBank))},
+		},
+	}
+}
+
+// exceptionTables lays out the discrepancies and the unmatched transactions of each side.
+func exceptionTables(report *domain.ReconciliationReport) []table {
+	discrepancies := table{
+		name:  "discrepancies",
+		title: "Discrepancies",
+		header: []string{
+			"system_trx_id", "system_type", "system_date", "system_amount", "system_currency",
+			"bank_unique_identifier", "bank_source", "bank_date", "bank_amount", "bank_currency",
+			"reason", "difference", "day_offset",
+		},
+	}
+	for _, d := range report.DiscrepantTransactions.Details {
+		sys, bank := d.SystemTransaction, d.BankTransaction
+		discrepancies.rows = append(discrepancies.rows, []cell{
+			text(sys.TrxID), text(string(sys.Type)), text(sys.TransactionTime.Format(time.DateOnly)), number(sys.Amount), text(string(sys.Currency)),
+			text(bank.UniqueIdentifier), text(bank.BankSource), text(bank.Date.Format(time.DateOnly)), number(bank.Amount), text(string(bank.Currency)),
+			text(string(d.Reason)), number(d.Difference), count(d.DayOffset),
+		})
+	}
+
+	systemMissing := table{
+		name:   "system_missing",
+		title:  "System Missing",
+		header: []string{"trx_id", "type", "transaction_time", "amount", "currency"},
+	}
+	for _, tx := range report.UnmatchedTransactions.SystemMissingFromBank {
+		systemMissing.rows = append(systemMissing.rows, []cell{
+			text(tx.TrxID), text(string(tx.Type)), text(tx.TransactionTime.Format(time.RFC3339)), number(tx.Amount), text(string(tx.Currency)),
+		})
+	}
+
+	bankMissing := table{
+		name:   "bank_missing",
+		title:  "Bank Missing",
+		header: []string{"bank_source", "unique_identifier", "date", "amount", "currency", "description"},
+	}
+	for _, source := range bankSources(report) {
+		for _, row := range bankRows(report.UnmatchedTransactions.BankMissingFromSystem[source]) {
+			bankMissing.rows = append(bankMissing.rows, append([]cell{text(source)}, row...))
+		}
+	}
+
+	return []table{discrepancies, systemMissing, bankMissing}
+}
+
+// bankSources returns the bank statements with unmatched transactions, by name.
+func bankSources(report *domain.ReconciliationReport) []string {
+	sources := make([]string, 0, len(report.UnmatchedTransactions.BankMissingFromSystem))
+	for source := range report.UnmatchedTransactions.BankMissingFromSystem {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// bankRows lays out bank transactions as unique_identifier, date, amount, currency and description.
+func bankRows(txs []domain.BankTransaction) [][]cell {
+	rows := make([][]cell, 0, len(txs))
+	for _, tx := range txs {
+		rows = append(rows, []cell{
+			text(tx.UniqueIdentifier), text(tx.Date.Format(time.DateOnly)), number(tx.Amount), text(string(tx.Currency)), text(tx.Description),
+		})
+	}
+	return rows
+}
+
+// unmatchedTotalsTable totals the unmatched transactions of each side by date, type and
+// currency. Bank transactions are typed by the sign of their statement amount.
+func unmatchedTotalsTable(report *domain.ReconciliationReport) table {
+	type key struct {
+		date     string
+		txType   domain.TransactionType
+		currency domain.Currency
+	}
+	type totals struct {
+		systemCount, bankCount   int
+		systemAmount, bankAmount domain.Decimal
+	}
+	byKey := make(map[key]*totals)
+	get := func(k key) *totals {
+		if byKey[k] == nil {
+			byKey[k] = &totals{}
+		}
+		return byKey[k]
+	}
+
+	for _, tx := range report.UnmatchedTransactions.SystemMissingFromBank {
+		t := get(key{tx.TransactionTime.Format(time.DateOnly), tx.Type, tx.Currency})
+		t.systemCount++
+		t.systemAmount = t.systemAmount.Add(tx.Amount)
+	}
+	for _, txs := range report.UnmatchedTransactions.BankMissingFromSystem {
+		for _, tx := range txs {
+			txType := domain.TransactionTypeCredit
+			if tx.Amount.Sign() < 0 {
+				txType = domain.TransactionTypeDebit
+			}
+			t := get(key{tx.Date.Format(time.DateOnly), txType, tx.Currency})
+			t.bankCount++
+			t.bankAmount = t.bankAmount.Add(tx.Amount.Abs())
+		}
+	}
+
+	keys := make([]key, 0, len(byKey))
+	for k := range byKey {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.date != b.date {
+			return a.date < b.date
+		}
+		if a.txType != b.txType {
+			return a.txType < b.txType
+		}
+		return a.currency < b.currency
+	})
+
+	t := table{
+		name:   "unmatched_totals",
+		title:  "Unmatched Totals",
+		header: []string{"date", "type", "currency", "system_count", "system_amount", "bank_count", "bank_amount"},
+	}
+	for _, k := range keys {
+		v := byKey[k]
+		t.rows = append(t.rows, []cell{
+			text(k.date), text(string(k.txType)), text(string(k.currency)),
+			count(v.systemCount), number(v.systemAmount), count(v.bankCount), number(v.bankAmount),
+		})
+	}
+	return t
+}
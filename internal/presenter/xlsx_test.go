@@ -0,0 +1,48 @@
+package presenter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteXLSX(&buf, sampleReport()))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	parts := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		assert.NoError(t, err)
+		// Every part must be well-formed XML
+		assert.NoError(t, xml.Unmarshal(content, new(struct{})), f.Name)
+		parts[f.Name] = string(content)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Summary" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Bank Missing" sheetId="4" r:id="rId4"/>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<c r="B6" s="0"><v>4</v></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet2.xml"], `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">system_trx_id</t></is></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet2.xml"], `<c r="I2" s="0"><v>-97.5</v></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet4.xml"], `<c r="F3" s="0" t="inlineStr"><is><t xml:space="preserve">Refund &#34;x&#34; &amp; &lt;y&gt;</t></is></c>`)
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, want, columnName(i))
+	}
+}
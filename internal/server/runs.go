@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/usecase"
+)
+
+// defaultRunListLimit is the number of runs listed when the request sets no limit.
+const defaultRunListLimit = 50
+
+// WithRuns enables background reconciliation runs, recorded by runs. At most workers runs
+// execute at once and up to queueSize more wait for a worker; further submissions are
+// rejected with 503 Service Unavailable.
+func WithRuns(runs *usecase.RunUseCase, workers, queueSize int) Option {
+	return func(s *Server) {
+		s.jobs = &jobQueue{
+			runs:    runs,
+			workers: max(workers, 1),
+			queue:   make(chan job, max(queueSize, 0)),
+		}
+	}
+}
+
+// job is a queued run with the request it reconciles.
+type job struct {
+	run domain.Run
+	req reconcileRequest
+}
+
+// jobQueue executes the submitted runs on a fixed number of workers.
+type jobQueue struct {
+	runs    *usecase.RunUseCase
+	workers int
+	queue   chan job
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+	ctx    context.Context // cancelled when Shutdown gives up waiting
+	cancel context.CancelFunc
+}
+
+func (q *jobQueue) start(s *Server) {
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for j := range q.queue {
+				s.execute(q.ctx, j)
+			}
+		}()
+	}
+}
+
+// submit queues a job, reporting false when the queue is full or shut down.
+func (q *jobQueue) submit(j job) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	select {
+	case q.queue <- j:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops accepting runs and waits for the queued and running ones to finish. When
+// ctx ends first, the remaining runs are cancelled and recorded as failed before it returns.
+// It does nothing unless WithRuns is given.
+func (s *Server) Shutdown(ctx context.Context) error {
+	q := s.jobs
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// execute runs a queued job and removes its uploads. A reconciliation that panics fails
+// the run rather than the worker, which goes on to the next job.
+func (s *Server) execute(ctx context.Context, j job) {
+	defer os.RemoveAll(j.req.dir)
+	run, err := s.jobs.runs.Execute(ctx, j.run, func(ctx context.Context) (report *domain.ReconciliationReport, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				s.logger.Printf("run %s panicked: %v\n%s", j.run.ID, p, debug.Stack())
+				report, err = nil, fmt.Errorf("internal error: %v", p)
+			}
+		}()
+		return s.reconcile(ctx, j.req)
+	})
+	if err != nil {
+		s.logger.Printf("run %s: %v", j.run.ID, err)
+		return
+	}
+	if run.Status == domain.RunFailed {
+		s.logger.Printf("run %s failed: %s", run.ID, run.Error)
+	}
+}
+
+// handleRuns submits a run (POST) or lists the recent runs (GET).
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.submitRun(w, r)
+	case http.MethodGet:
+		limit := defaultRunListLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+				return
+			}
+			limit = n
+		}
+		runs, err := s.jobs.runs.List(r.Context(), limit)
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, runs)
+	default:
+		s.notAllowed(w, r, "GET, POST")
+	}
+}
+
+func (s *Server) submitRun(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.receive(w, r)
+	if !ok {
+		return
+	}
+
+	params := usecase.NewReconciliationUseCase(nil, s.useCaseOpts...).RunParameters(req.start, req.end)
+	run, err := s.jobs.runs.Submit(r.Context(), params, req.inputs)
+	if err != nil {
+		os.RemoveAll(req.dir)
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !s.jobs.submit(job{run: run, req: req}) {
+		os.RemoveAll(req.dir)
+		if _, err := s.jobs.runs.Fail(r.Context(), run, "rejected: the run queue is full"); err != nil {
+			s.logger.Printf("run %s: %v", run.ID, err)
+		}
+		w.Header().Set("Retry-After", "60")
+		s.writeError(w, http.StatusServiceUnavailable, errors.New("too many runs in progress, try again later"))
+		return
+	}
+
+	w.Header().Set("Location", "/runs/"+run.ID)
+	s.writeJSON(w, http.StatusAccepted, run)
+}
+
+// handleRun serves /runs/{id} and /runs/{id}/report.
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.notAllowed(w, r, http.MethodGet)
+		return
+	}
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/runs/"), "/")
+	if id == "" || (rest != "" && rest != "report") {
+		http.NotFound(w, r)
+		return
+	}
+
+	run, err := s.jobs.runs.Get(r.Context(), id)
+	if err != nil {
+		s.writeStoreError(w, err)
+		return
+	}
+	if rest == "" {
+		s.writeJSON(w, http.StatusOK, run)
+		return
+	}
+
+	if run.Status != domain.RunSucceeded {
+		s.writeError(w, http.StatusConflict, fmt.Errorf("run %s has no report: it is %s", id, run.Status))
+		return
+	}
+	report, err := s.jobs.runs.Report(r.Context(), id)
+	if err != nil {
+		s.writeStoreError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) writeStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrRunNotFound) {
+		s.writeError(w, http.StatusNotFound, err)
+		return
+	}
+	s.writeError(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,322 @@
+// Package server exposes the reconciliation usecase as an HTTP API.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/gateway"
+	"mini-reconciliation/internal/usecase"
+)
+
+// DefaultMaxUploadBytes is the largest request body accepted unless WithMaxUploadBytes is given.
+const DefaultMaxUploadBytes = 32 << 20
+
+// maxFieldBytes is the largest value accepted for a non-file form field.
+const maxFieldBytes = 1 << 10
+
+// Server serves reconciliations of uploaded system and bank statement files.
+type Server struct {
+	repoOpts       []gateway.Option
+	useCaseOpts    []usecase.Option
+	maxUploadBytes int64
+	logger         *log.Logger
+	jobs           *jobQueue // nil unless WithRuns is given
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithRepositoryOptions sets the options of the repository reading each request's uploads,
+// e.g. the bank profiles. Statements are matched to a profile by their uploaded file name.
+func WithRepositoryOptions(opts ...gateway.Option) Option {
+	return func(s *Server) {
+		s.repoOpts = opts
+	}
+}
+
+// WithUseCaseOptions sets the options every reconciliation runs with. They are shared by
+// concurrent requests, so they must not carry per-run state such as an open item store.
+func WithUseCaseOptions(opts ...usecase.Option) Option {
+	return func(s *Server) {
+		s.useCaseOpts = opts
+	}
+}
+
+// WithMaxUploadBytes limits the size of a request body; larger requests are rejected
+// with 413 Request Entity Too Large.
+func WithMaxUploadBytes(n int64) Option {
+	return func(s *Server) {
+		s.maxUploadBytes = n
+	}
+}
+
+// WithLogger sets where failed requests are logged. It defaults to the standard logger.
+func WithLogger(logger *log.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// New creates a server.
+func New(opts ...Option) *Server {
+	s := &Server{
+		maxUploadBytes: DefaultMaxUploadBytes,
+		logger:         log.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.jobs != nil {
+		s.jobs.start(s)
+	}
+	return s
+}
+
+// Handler returns the HTTP handler of the API:
+//
+//	POST /reconcile  multipart form with a "system" file, one or more "bank" files and
+//	                 the "start" and "end" dates (YYYY-MM-DD); responds with the report JSON
+//	GET  /healthz    responds 200 while the server is up
+//
+// With WithRuns, reconciliations can also run in the background:
+//
+//	POST /runs              the form of /reconcile; responds 202 with the queued run
+//	GET  /runs              the most recent runs first, at most ?limit= of them (default 50)
+//	GET  /runs/{id}         the run with its status
+//	GET  /runs/{id}/report  the report of a succeeded run
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/reconcile", s.handleReconcile)
+	if s.jobs != nil {
+		mux.HandleFunc("/runs", s.handleRuns)
+		mux.HandleFunc("/runs/", s.handleRun)
+	}
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+func (s *Server) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.notAllowed(w, r, http.MethodPost)
+		return
+	}
+	req, ok := s.receive(w, r)
+	if !ok {
+		return
+	}
+	defer os.RemoveAll(req.dir)
+
+	report, err := s.reconcile(r.Context(), req)
+	if err != nil {
+		if r.Context().Err() != nil {
+			// The client went away; there is nobody left to answer
+			return
+		}
+		s.writeError(w, reconcileStatus(err), err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// reconcileStatus is the status answering a failed reconciliation: 422 when the uploaded
+// files cannot be reconciled, 500 when the server failed, e.g. to read them back.
+func reconcileStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidInput) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// reconcileRequest is a reconciliation request whose files were stored in a directory.
+// Its sources read the stored files under the names they were uploaded with.
+type reconcileRequest struct {
+	dir        string
+	system     domain.Source
+	banks      []domain.Source
+	inputs     []domain.RunInput
+	start, end time.Time
+}
+
+// receive stores the files of a reconcile request in a new temporary directory, which the
+// caller removes once done. A request that cannot be received is answered here, unless its
+// client went away.
+func (s *Server) receive(w http.ResponseWriter, r *http.Request) (reconcileRequest, bool) {
+	r.Body = http.MaxBytesReader(w, contextBody{ctx: r.Context(), ReadCloser: r.Body}, s.maxUploadBytes)
+
+	dir, err := os.MkdirTemp("", "reconcile-upload-")
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, fmt.Errorf("could not store uploads: %w", err))
+		return reconcileRequest{}, false
+	}
+
+	req, err := receiveUpload(r, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		if r.Context().Err() != nil {
+			return req, false
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+			return req, false
+		}
+		s.writeError(w, http.StatusBadRequest, err)
+		return req, false
+	}
+	req.dir = dir
+	return req, true
+}
+
+// reconcile runs the reconciliation of a received request.
+func (s *Server) reconcile(ctx context.Context, req reconcileRequest) (*domain.ReconciliationReport, error) {
+	repo := gateway.NewCSVTransactionRepository(s.repoOpts...)
+	uc := usecase.NewReconciliationUseCase(repo, s.useCaseOpts...)
+	return uc.Reconcile(ctx, req.system, req.banks, req.start, req.end)
+}
+
+// receiveUpload reads the multipart form of a reconcile request, storing its files in dir
+// under their uploaded names so that bank profiles match them by file name.
+func receiveUpload(r *http.Request, dir string) (reconcileRequest, error) {
+	var req reconcileRequest
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return req, fmt.Errorf("expected a multipart/form-data request: %w", err)
+	}
+
+	var start, end string
+	stored := make(map[string]bool)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return req, fmt.Errorf("could not read upload: %w", err)
+		}
+
+		switch name := part.FormName(); name {
+		case "system", "bank":
+			source, input, err := storePart(part, dir, stored)
+			if err != nil {
+				return req, err
+			}
+			req.inputs = append(req.inputs, input)
+			if name == "bank" {
+				req.banks = append(req.banks, source)
+			} else if req.system.Name != "" {
+				return req, errors.New("only one system file may be uploaded")
+			} else {
+				req.system = source
+			}
+		case "start", "end":
+			value, err := io.ReadAll(io.LimitReader(part, maxFieldBytes))
+			if err != nil {
+				return req, fmt.Errorf("could not read field %s: %w", name, err)
+			}
+			if name == "start" {
+				start = string(value)
+			} else {
+				end = string(value)
+			}
+		default:
+			return req, fmt.Errorf("unexpected form field %q", name)
+		}
+	}
+
+	if req.system.Name == "" || len(req.banks) == 0 || start == "" || end == "" {
+		return req, errors.New("the system and bank files and the start and end dates are required")
+	}
+	if req.start, err = time.Parse(time.DateOnly, strings.TrimSpace(start)); err != nil {
+		return req, fmt.Errorf("invalid start date: %w", err)
+	}
+	if req.end, err = time.Parse(time.DateOnly, strings.TrimSpace(end)); err != nil {
+		return req, fmt.Errorf("invalid end date: %w", err)
+	}
+	return req, nil
+}
+
+// storePart writes an uploaded file to dir and returns the source reading it under the base
+// name it was uploaded with, and its checksum. Bank transactions are reported by statement
+// file name, so names must be unique.
+func storePart(part *multipart.Part, dir string, stored map[string]bool) (domain.Source, domain.RunInput, error) {
+	input := domain.RunInput{Kind: part.FormName(), Name: filepath.Base(part.FileName())}
+	if part.FileName() == "" || input.Name == "." || input.Name == ".." || input.Name == string(filepath.Separator) {
+		return domain.Source{}, input, fmt.Errorf("field %s must be a file upload with a file name", part.FormName())
+	}
+	if stored[input.Name] {
+		return domain.Source{}, input, fmt.Errorf("file %s was uploaded more than once", input.Name)
+	}
+	stored[input.Name] = true
+
+	path := filepath.Join(dir, input.Name)
+	file, err := os.Create(path)
+	if err != nil {
+		return domain.Source{}, input, fmt.Errorf("could not store %s: %w", input.Name, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if input.Size, err = io.Copy(io.MultiWriter(file, hash), part); err != nil {
+		return domain.Source{}, input, fmt.Errorf("could not store %s: %w", input.Name, err)
+	}
+	input.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	source := domain.Source{Name: input.Name, Open: func() (io.ReadCloser, error) { return os.Open(path) }}
+	return source, input, file.Close()
+}
+
+// contextBody is a request body that stops reading once ctx is done, so that the upload
+// of a client that went away is not parsed to the end.
+type contextBody struct {
+	ctx context.Context
+	io.ReadCloser
+}
+
+func (b contextBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return b.ReadCloser.Read(p)
+}
+
+// errorResponse is the body of a failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		s.logger.Printf("reconcile: %v", err)
+	}
+	s.writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func (s *Server) notAllowed(w http.ResponseWriter, r *http.Request, allowed string) {
+	w.Header().Set("Allow", allowed)
+	s.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+}
+
+// writeJSON responds with v as indented JSON, the same as the CLI prints a report.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		s.logger.Printf("reconcile: %v", err)
+	}
+}
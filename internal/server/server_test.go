@@ -0,0 +1,391 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"mini-reconciliation/internal/domain"
+	"mini-reconciliation/internal/gateway"
+	"mini-reconciliation/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	systemCSV = "trxID,amount,type,transactionTime\n" +
+		"SYS001,150.00,DEBIT,2025-09-01T10:00:00Z\n" +
+		"SYS002,200.50,CREDIT,2025-09-02T11:30:00Z\n"
+	bankCSV = "unique_identifier,amount,date,description\n" +
+		"BANK_A_1,-150.00,2025-09-01,Payment\n" +
+		"BANK_A_2,99.00,2025-09-03,Deposit\n"
+)
+
+// upload is one part of a multipart request: a file when filename is set, else a field.
+type upload struct {
+	field, filename, content string
+}
+
+func newUploadRequest(t *testing.T, parts ...upload) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		if p.filename == "" {
+			require.NoError(t, mw.WriteField(p.field, p.content))
+			continue
+		}
+		w, err := mw.CreateFormFile(p.field, p.filename)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(p.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/reconcile", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestServer_Reconcile(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().Handler().ServeHTTP(rec, newUploadRequest(t,
+		upload{field: "start", content: "2025-09-01"},
+		upload{field: "end", content: "2025-09-05"},
+		upload{field: "system", filename: "system.csv", content: systemCSV},
+		upload{field: "bank", filename: "statement_bank_A.csv", content: bankCSV},
+	))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var report domain.ReconciliationReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.ReconciliationSummary.TotalSystemTransactionsProcessed)
+	assert.Equal(t, 2, report.UnmatchedTransactions.Count)
+	// Bank transactions keep the name they were uploaded with
+	assert.Len(t, report.UnmatchedTransactions.BankMissingFromSystem["statement_bank_A.csv"], 1)
+}
+
+func TestServer_Reconcile_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		server     *Server
+		req        func(t *testing.T) *http.Request
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:   "wrong method",
+			server: New(),
+			req: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/reconcile", nil)
+			},
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "not multipart",
+			server: New(),
+			req: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/reconcile", bytes.NewBufferString("{}"))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "expected a multipart/form-data request",
+		},
+		{
+			name:   "missing bank file",
+			server: New(),
+			req: func(t *testing.T) *http.Request {
+				return newUploadRequest(t,
+					upload{field: "start", content: "2025-09-01"},
+					upload{field: "end", content: "2025-09-05"},
+					upload{field: "system", filename: "system.csv", content: systemCSV},
+				)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "required",
+		},
+		{
+			name:   "duplicate file name",
+			server: New(),
+			req: func(t *testing.T) *http.Request {
+				return newUploadRequest(t,
+					upload{field: "bank", filename: "bank.csv", content: bankCSV},
+					upload{field: "bank", filename: "bank.csv", content: bankCSV},
+				)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "uploaded more than once",
+		},
+		{
+			name:   "body too large",
+			server: New(WithMaxUploadBytes(64)),
+			req: func(t *testing.T) *http.Request {
+				return newUploadRequest(t, upload{field: "system", filename: "system.csv", content: systemCSV})
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "unreadable file",
+			server: New(),
+			req: func(t *testing.T) *http.Request {
+				return newUploadRequest(t,
+					upload{field: "start", content: "2025-09-01"},
+					upload{field: "end", content: "2025-09-05"},
+					upload{field: "system", filename: "system.csv", content: "trxID,amount\nSYS001,1\n"},
+					upload{field: "bank", filename: "bank.csv", content: bankCSV},
+				)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  `file system.csv is missing required column "type"`,
+		},
+		{
+			name:   "bad row",
+			server: New(),
+			req: func(t *testing.T) *http.Request {
+				return newUploadRequest(t,
+					upload{field: "start", content: "2025-09-01"},
+					upload{field: "end", content: "2025-09-05"},
+					upload{field: "system", filename: "system.csv", content: "trxID,amount,type,transactionTime\nSYS001,abc,DEBIT,2025-09-01T10:00:00Z\n"},
+					upload{field: "bank", filename: "bank.csv", content: bankCSV},
+				)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "could not parse amount 'abc'",
+		},
+		{
+			name:   "server failure",
+			server: New(WithLogger(log.New(io.Discard, "", 0)), WithUseCaseOptions(usecase.WithMatchers(failingMatcher{}))),
+			req: func(t *testing.T) *http.Request {
+				return newUploadRequest(t,
+					upload{field: "start", content: "2025-09-01"},
+					upload{field: "end", content: "2025-09-05"},
+					upload{field: "system", filename: "system.csv", content: systemCSV},
+					upload{field: "bank", filename: "bank.csv", content: bankCSV},
+				)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "failing matching failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.server.Handler().ServeHTTP(rec, tt.req(t))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var body errorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Contains(t, body.Error, tt.wantError)
+		})
+	}
+}
+
+// failingMatcher stands in for a matching pass failing for reasons of its own.
+type failingMatcher struct{}
+
+func (failingMatcher) Name() string { return "failing" }
+
+func (failingMatcher) Match(ctx context.Context, state *usecase.MatchState) error {
+	return errors.New("index unavailable")
+}
+
+func TestServer_Reconcile_ClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := newUploadRequest(t,
+		upload{field: "start", content: "2025-09-01"},
+		upload{field: "end", content: "2025-09-05"},
+		upload{field: "system", filename: "system.csv", content: systemCSV},
+		upload{field: "bank", filename: "statement_bank_A.csv", content: bankCSV},
+	).WithContext(ctx)
+	body := &countingReader{r: req.Body}
+	req.Body = io.NopCloser(body)
+
+	// The upload is not parsed and nobody is left to answer
+	rec := httptest.NewRecorder()
+	New().Handler().ServeHTTP(rec, req)
+	assert.Zero(t, body.n)
+	assert.Empty(t, rec.Body.String())
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func newRunServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	store, err := gateway.OpenSQLiteRunStore(filepath.Join(t.TempDir(), "runs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	s := New(append(opts, WithRuns(usecase.NewRunUseCase(store), 1, 10))...)
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return s
+}
+
+func TestServer_Runs(t *testing.T) {
+	s := newRunServer(t, WithUseCaseOptions(
+		usecase.WithSettlementLag(1, false),
+		usecase.WithMatchers(append(usecase.DefaultMatchers(), usecase.ToleranceMatcher(domain.MustParseDecimal("0.5"), domain.Decimal{}))...),
+	))
+	handler := s.Handler()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	req := newUploadRequest(t,
+		upload{field: "start", content: "2025-09-01"},
+		upload{field: "end", content: "2025-09-05"},
+		upload{field: "system", filename: "system.csv", content: systemCSV},
+		upload{field: "bank", filename: "statement_bank_A.csv", content: bankCSV},
+	)
+	req.URL.Path = "/runs"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	var submitted domain.Run
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &submitted))
+	assert.Equal(t, "/runs/"+submitted.ID, rec.Header().Get("Location"))
+	tolerance, zero := domain.MustParseDecimal("0.5"), domain.Decimal{}
+	params := domain.RunParameters{
+		Start: "2025-09-01", End: "2025-09-05", ReportingCurrency: "IDR", SettlementLagDays: 1,
+		ToleranceAbsolute: &tolerance, TolerancePercent: &zero, Passes: []string{"reference", "exact", "group", "tolerance"},
+	}
+	assert.Equal(t, params, submitted.Parameters)
+	require.Len(t, submitted.Inputs, 2)
+	assert.Equal(t, domain.RunInput{Kind: "system", Name: "system.csv", Size: int64(len(systemCSV)), SHA256: sha256Hex(systemCSV)}, submitted.Inputs[0])
+
+	// Wait for the worker, then fetch the report
+	require.NoError(t, s.Shutdown(context.Background()))
+	var run domain.Run
+	require.NoError(t, json.Unmarshal(get("/runs/"+submitted.ID).Body.Bytes(), &run))
+	assert.Equal(t, domain.RunSucceeded, run.Status)
+	assert.Equal(t, params, run.Parameters)
+	assert.NotNil(t, run.FinishedAt)
+
+	rec = get("/runs/" + submitted.ID + "/report")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var report domain.ReconciliationReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Len(t, report.UnmatchedTransactions.BankMissingFromSystem["statement_bank_A.csv"], 1)
+
+	var runs []domain.Run
+	require.NoError(t, json.Unmarshal(get("/runs?limit=10").Body.Bytes(), &runs))
+	assert.Len(t, runs, 1)
+
+	assert.Equal(t, http.StatusNotFound, get("/runs/unknown").Code)
+	assert.Equal(t, http.StatusNotFound, get("/runs/"+submitted.ID+"/other").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/runs?limit=x").Code)
+}
+
+func TestServer_Runs_Failed(t *testing.T) {
+	s := newRunServer(t)
+	handler := s.Handler()
+	req := newUploadRequest(t,
+		upload{field: "start", content: "2025-09-01"},
+		upload{field: "end", content: "2025-09-05"},
+		upload{field: "system", filename: "system.csv", content: "trxID,amount\nSYS001,1\n"},
+		upload{field: "bank", filename: "bank.csv", content: bankCSV},
+	)
+	req.URL.Path = "/runs"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var run domain.Run
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &run))
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/"+run.ID, nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &run))
+	assert.Equal(t, domain.RunFailed, run.Status)
+	assert.Contains(t, run.Error, `file system.csv is missing required column "type"`)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/"+run.ID+"/report", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// No runs are accepted once shut down
+	req = newUploadRequest(t,
+		upload{field: "start", content: "2025-09-01"},
+		upload{field: "end", content: "2025-09-05"},
+		upload{field: "system", filename: "system.csv", content: systemCSV},
+		upload{field: "bank", filename: "bank.csv", content: bankCSV},
+	)
+	req.URL.Path = "/runs"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+// panickingMatcher stands in for a bug in a matching pass.
+type panickingMatcher struct{}
+
+func (panickingMatcher) Name() string { return "panicking" }
+
+func (panickingMatcher) Match(ctx context.Context, state *usecase.MatchState) error {
+	panic("boom")
+}
+
+func TestServer_Runs_Panic(t *testing.T) {
+	var logs bytes.Buffer
+	s := newRunServer(t, WithLogger(log.New(&logs, "", 0)), WithUseCaseOptions(usecase.WithMatchers(panickingMatcher{})))
+	handler := s.Handler()
+
+	// The worker outlives the first run's panic to take the second
+	var ids []string
+	for i := 0; i < 2; i++ {
+		req := newUploadRequest(t,
+			upload{field: "start", content: "2025-09-01"},
+			upload{field: "end", content: "2025-09-05"},
+			upload{field: "system", filename: "system.csv", content: systemCSV},
+			upload{field: "bank", filename: "statement_bank_A.csv", content: bankCSV},
+		)
+		req.URL.Path = "/runs"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		var run domain.Run
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &run))
+		ids = append(ids, run.ID)
+	}
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	for _, id := range ids {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/"+id, nil))
+		var run domain.Run
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &run))
+		assert.Equal(t, domain.RunFailed, run.Status)
+		assert.Equal(t, "internal error: boom", run.Error)
+		assert.NotNil(t, run.FinishedAt)
+	}
+	assert.Contains(t, logs.String(), "panicked: boom")
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
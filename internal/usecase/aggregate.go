@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+const (
+	// maxAggregateCandidates bounds how many transactions are considered for one subset search.
+	maxAggregateCandidates = 32
+	// maxAggregateSteps bounds the work of one subset search.
+	maxAggregateSteps = 100000
+	// aggregateConfidence is the confidence of a match on a sum of amounts.
+	aggregateConfidence = 0.6
+)
+
+type aggregateMatcher struct {
+	maxSize int
+	phase   aggregatePhase
+}
+
+// aggregatePhase restricts aggregate matching to one of its directions.
+type aggregatePhase int
+
+const (
+	bothDirections aggregatePhase = iota
+	systemSums                    // many system transactions settled as one bank transaction
+	bankSums                      // one system transaction split across several bank transactions
+)
+
+// AggregateMatcher pairs a single bank transaction with 2..maxSize system transactions whose
+// amounts sum to it exactly (a gateway settling many payments in one credit), then a single
+// system transaction with several bank transactions (one payout split across debits).
+// Candidates must share the transaction type and fall within the settlement window.
+func AggregateMatcher(maxSize int) Matcher {
+	return aggregateMatcher{maxSize: maxSize}
+}
+
+func (aggregateMatcher) Name() string {
+	return "aggregate"
+}
+
+func (m aggregateMatcher) describe(params *domain.RunParameters) {
+	params.AggregateMax = m.maxSize
+}
+
+// phases splits aggregate matching into its two directions, each run on every date in turn.
+func (m aggregateMatcher) phases() []Matcher {
+	if m.phase != bothDirections {
+		return []Matcher{m}
+	}
+	sums, splits := m, m
+	sums.phase, splits.phase = systemSums, bankSums
+	return []Matcher{sums, splits}
+}
+
+func (m aggregateMatcher) Match(ctx context.Context, state *MatchState) error {
+	if m.phase != bankSums {
+		m.matchSystemSums(state)
+	}
+	if m.phase != systemSums {
+		m.matchBankSums(state)
+	}
+	return nil
+}
+
+// matchSystemSums pairs a bank transaction with several system transactions summing to it.
+func (m aggregateMatcher) matchSystemSums(state *MatchState) {
+	systemByDay := bucketByDay(state, state.UnmatchedSystem(), func(tx domain.SystemTransaction) (domain.TransactionType, time.Time) {
+		return tx.Type, tx.TransactionTime
+	})
+	for _, bankTx := range state.UnmatchedBank() {
+		var candidates []domain.SystemTransaction
+		var amounts []domain.Decimal
+		for _, sysTx := range systemByDay.within(state, bankTx.Type, bankTx.Date) {
+			if state.IsSystemMatched(sysTx) || !state.WithinSettlementWindow(sysTx.TransactionTime, bankTx.Date) {
+				continue
+			}
+			candidates = append(candidates, sysTx)
+			amounts = append(amounts, state.SystemAmount(sysTx))
+		}
+
+		subset := findSubsetSum(amounts, state.BankAmount(bankTx), m.maxSize)
+		if subset == nil {
+			continue
+		}
+		sysTxs := make([]domain.SystemTransaction, 0, len(subset))
+		for _, i := range subset {
+			sysTxs = append(sysTxs, candidates[i])
+		}
+		state.MatchGroup(sysTxs, []domain.BankTransaction{bankTx}, Evidence{
+			Key:        fmt.Sprintf("%s sum of %d system transactions", buildGroupKey(bankTx.Type, state.BankAmount(bankTx)), len(sysTxs)),
+			Confidence: aggregateConfidence,
+		})
+	}
+}
+
+// matchBankSums pairs a system transaction with several bank transactions summing to it.
+func (m aggregateMatcher) matchBankSums(state *MatchState) {
+	bankByDay := bucketByDay(state, state.UnmatchedBank(), func(tx domain.BankTransaction) (domain.TransactionType, time.Time) {
+		return tx.Type, tx.Date
+	})
+	for _, sysTx := range state.UnmatchedSystem() {
+		var candidates []domain.BankTransaction
+		var amounts []domain.Decimal
+		for _, bankTx := range bankByDay.within(state, sysTx.Type, sysTx.TransactionTime) {
+			if state.IsBankMatched(bankTx) || !state.WithinSettlementWindow(sysTx.TransactionTime, bankTx.Date) {
+				continue
+			}
+			candidates = append(candidates, bankTx)
+			amounts = append(amounts, state.BankAmount(bankTx))
+		}
+
+		subset := findSubsetSum(amounts, state.SystemAmount(sysTx), m.maxSize)
+		if subset == nil {
+			continue
+		}
+		bankTxs := make([]domain.BankTransaction, 0, len(subset))
+		for _, i := range subset {
+			bankTxs = append(bankTxs, candidates[i])
+		}
+		state.MatchGroup([]domain.SystemTransaction{sysTx}, bankTxs, Evidence{
+			Key:        fmt.Sprintf("%s sum of %d bank transactions", buildGroupKey(sysTx.Type, state.SystemAmount(sysTx)), len(bankTxs)),
+			Confidence: aggregateConfidence,
+		})
+	}
+}
+
+// dayBuckets holds transactions by type and date, each date in the order the transactions
+// were added.
+type dayBuckets[T any] map[domain.TransactionType]map[time.Time][]T
+
+func bucketByDay[T any](state *MatchState, txs []T, key func(T) (domain.TransactionType, time.Time)) dayBuckets[T] {
+	buckets := make(dayBuckets[T])
+	for _, tx := range txs {
+		txType, date := key(tx)
+		byDay := buckets[txType]
+		if byDay == nil {
+			byDay = make(map[time.Time][]T)
+			buckets[txType] = byDay
+		}
+		day := state.settlement.day(date)
+		byDay[day] = append(byDay[day], tx)
+	}
+	return buckets
+}
+
+// within returns the transactions of the type dated within the settlement window's reach
+// of date, in date order. Callers still check the window itself and whether each
+// transaction has been matched since the buckets were built.
+func (b dayBuckets[T]) within(state *MatchState, txType domain.TransactionType, date time.Time) []T {
+	byDay := b[txType]
+	if len(byDay) == 0 {
+		return nil
+	}
+	var txs []T
+	day, span := state.settlement.day(date), state.settlement.span()
+	for offset := -span; offset <= span; offset++ {
+		txs = append(txs, byDay[day.AddDate(0, 0, offset)]...)
+	}
+	return txs
+}
+
+// findSubsetSum returns the indexes (in input order) of between 2 and maxSize positive amounts
+// that sum exactly to target, or nil if there is none. The search is bounded in both the
+// number of candidates and the steps taken, so it may miss a solution on very dense days.
+func findSubsetSum(amounts []domain.Decimal, target domain.Decimal, maxSize int) []int {
+	if maxSize < 2 || len(amounts) < 2 || target.Sign() <= 0 {
+		return nil
+	}
+
+	order := make([]int, 0, len(amounts))
+	for i, a := range amounts {
+		if a.Sign() > 0 && a.Cmp(target) <= 0 {
+			order = append(order, i)
+		}
+	}
+	if len(order) > maxAggregateCandidates {
+		order = order[:maxAggregateCandidates]
+	}
+	// Largest amounts first so the running sum overshoots, and is pruned, early
+	sort.SliceStable(order, func(i, j int) bool { return amounts[order[i]].Cmp(amounts[order[j]]) > 0 })
+
+	// remaining[k] is the sum of amounts[order[k:]], used to prune branches that cannot reach target
+	remaining := make([]domain.Decimal, len(order)+1)
+	for k := len(order) - 1; k >= 0; k-- {
+		remaining[k] = remaining[k+1].Add(amounts[order[k]])
+	}
+
+	var chosen []int
+	steps := 0
+	var search func(start int, sum domain.Decimal) bool
+	search = func(start int, sum domain.Decimal) bool {
+		if cmp := sum.Cmp(target); cmp == 0 {
+			return len(chosen) >= 2
+		} else if cmp > 0 {
+			return false
+		}
+		if len(chosen) == maxSize || sum.Add(remaining[start]).Cmp(target) < 0 {
+			return false
+		}
+		for k := start; k < len(order); k++ {
+			if steps++; steps > maxAggregateSteps {
+				return false
+			}
+			chosen = append(chosen, order[k])
+			if search(k+1, sum.Add(amounts[order[k]])) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	if !search(0, domain.Decimal{}) {
+		return nil
+	}
+
+	sort.Ints(chosen)
+	return chosen
+}
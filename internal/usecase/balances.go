@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// periodMovements totals the transactions of the period for balance reconciliation. Items
+// carried in from earlier periods are not movements of this period and are left out.
+type periodMovements struct {
+	bank         map[string]domain.Decimal  // statement amounts by bank source, in the statement's currency
+	bankCurrency map[string]domain.Currency // currency of each statement's transactions by bank source
+	ledger       domain.Decimal             // system amounts in the reporting currency, debits negative
+}
+
+func newPeriodMovements() periodMovements {
+	return periodMovements{bank: make(map[string]domain.Decimal), bankCurrency: make(map[string]domain.Currency)}
+}
+
+func (m *periodMovements) addBank(tx domain.BankTransaction) {
+	m.bank[tx.BankSource] = m.bank[tx.BankSource].Add(tx.Amount)
+	m.bankCurrency[tx.BankSource] = domain.ParseCurrency(string(tx.Currency))
+}
+
+// addSystem adds a system transaction with its amount in the reporting currency.
+func (m *periodMovements) addSystem(tx domain.SystemTransaction, amount domain.Decimal) {
+	if tx.Type == domain.TransactionTypeDebit {
+		amount = amount.Neg()
+	}
+	m.ledger = m.ledger.Add(amount)
+}
+
+// reconcileBalances checks each statement's opening balance plus its movements in the
+// period against its closing balance and, given the ledger opening balance, compares the
+// system's expected ledger balance with the bank closing balances. Statement movements are
+// in the statement's currency; the ledger is compared in the reporting currency, each
+// closing balance converted at the rate of the period's end.
+func (uc *ReconciliationUseCase) reconcileBalances(ctx context.Context, bankSources []domain.Source, movements periodMovements, end time.Time) (*domain.Balances, error) {
+	statements, err := uc.repo.GetBankBalances(ctx, bankSources)
+	if err != nil {
+		return nil, err
+	}
+
+	bySource := make(map[string]*domain.BankBalance)
+	bank := func(source string) *domain.BankBalance {
+		if b, ok := bySource[source]; ok {
+			return b
+		}
+		b := &domain.BankBalance{StatementBalance: domain.StatementBalance{BankSource: source}}
+		bySource[source] = b
+		return b
+	}
+	// Balances given explicitly take precedence over those printed on the statements
+	for _, balances := range [][]domain.StatementBalance{statements, uc.statementBalances} {
+		for _, sb := range balances {
+			b := bank(sb.BankSource)
+			if sb.Currency != "" {
+				b.Currency = sb.Currency
+			}
+			if sb.Opening != nil {
+				b.Opening = sb.Opening
+			}
+			if sb.Closing != nil {
+				b.Closing = sb.Closing
+			}
+		}
+	}
+	for source, amount := range movements.bank {
+		b := bank(source)
+		b.Movements = amount
+	}
+
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	result := &domain.Balances{Banks: make([]domain.BankBalance, 0, len(bySource))}
+	var closingTotal domain.Decimal
+	allClosed := len(bySource) > 0
+	for _, source := range sources {
+		b := bySource[source]
+		// Balances with no currency of their own are in that of the statement's transactions
+		if b.Currency == "" {
+			b.Currency = movements.bankCurrency[source]
+		}
+		if b.Currency == "" {
+			b.Currency = uc.reportingCurrency
+		}
+
+		if b.Opening != nil {
+			expected := b.Opening.Add(b.Movements)
+			b.ExpectedClosing = &expected
+			if b.Closing != nil {
+				diff := b.Closing.Sub(expected)
+				balanced := diff.IsZero()
+				b.Difference = &diff
+				b.Balanced = &balanced
+			}
+		}
+		if b.Closing != nil {
+			closing, err := uc.toReporting(ctx, *b.Closing, b.Currency, end)
+			if err != nil {
+				return nil, fmt.Errorf("closing balance of %s: %w", source, err)
+			}
+			b.ClosingFX = closing.fx
+			closingTotal = closingTotal.Add(closing.amount)
+		} else {
+			allClosed = false
+		}
+		result.Banks = append(result.Banks, *b)
+	}
+
+	if uc.ledgerOpening != nil {
+		ledger := &domain.LedgerBalance{Currency: uc.reportingCurrency, Opening: *uc.ledgerOpening, Movements: movements.ledger}
+		ledger.ExpectedClosing = ledger.Opening.Add(ledger.Movements)
+		if allClosed {
+			diff := closingTotal.Sub(ledger.ExpectedClosing)
+			balanced := diff.IsZero()
+			ledger.BankClosingTotal = &closingTotal
+			ledger.Difference = &diff
+			ledger.Balanced = &balanced
+		}
+		result.Ledger = ledger
+	}
+	return result, nil
+}
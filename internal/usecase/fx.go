@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// FXRateProvider supplies the exchange rates used to bring amounts into the reporting currency.
+//
+//go:generate mockgen -destination=mocks/mock_fx.go -source=fx.go FXRateProvider
+type FXRateProvider interface {
+	Rate(ctx context.Context, from, to domain.Currency, date time.Time) (domain.FXRate, error)
+}
+
+// convertedAmount is a transaction amount expressed in the reporting currency.
+type convertedAmount struct {
+	amount domain.Decimal
+	fx     *domain.FXConversion // nil when no conversion was needed
+}
+
+// toReporting converts an amount into the reporting currency using the rate effective on date.
+func (uc *ReconciliationUseCase) toReporting(ctx context.Context, amount domain.Decimal, currency domain.Currency, date time.Time) (convertedAmount, error) {
+	currency = domain.ParseCurrency(string(currency))
+	if currency == uc.reportingCurrency {
+		return convertedAmount{amount: amount}, nil
+	}
+	if uc.fxRates == nil {
+		return convertedAmount{}, domain.InvalidInput(fmt.Errorf("no FX rate source configured to convert %s to %s", currency, uc.reportingCurrency))
+	}
+
+	rate, err := uc.fxRates.Rate(ctx, currency, uc.reportingCurrency, date)
+	if err != nil {
+		return convertedAmount{}, fmt.Errorf("could not get FX rate %s/%s for %s: %w", currency, uc.reportingCurrency, date.Format(time.DateOnly), err)
+	}
+
+	converted := uc.reportingCurrency.Round(amount.Mul(rate.Rate))
+	return convertedAmount{
+		amount: converted,
+		fx: &domain.FXConversion{
+			From:            currency,
+			To:              uc.reportingCurrency,
+			Rate:            rate.Rate,
+			RateDate:        rate.Date.Format(time.DateOnly),
+			OriginalAmount:  amount,
+			ConvertedAmount: converted,
+		},
+	}, nil
+}
+
+// convertAmounts expresses every transaction amount in the reporting currency, keyed by
+// system TrxID and bank statement and UniqueIdentifier respectively.
+func (uc *ReconciliationUseCase) convertAmounts(ctx context.Context, systemTxs []domain.SystemTransaction, bankTxs []domain.BankTransaction) (map[string]convertedAmount, map[bankKey]convertedAmount, error) {
+	systemAmounts := make(map[string]convertedAmount, len(systemTxs))
+	for _, tx := range systemTxs {
+		converted, err := uc.toReporting(ctx, tx.Amount, tx.Currency, tx.TransactionTime)
+		if err != nil {
+			return nil, nil, fmt.Errorf("system transaction %s: %w", tx.TrxID, err)
+		}
+		systemAmounts[tx.TrxID] = converted
+	}
+
+	bankAmounts := make(map[bankKey]convertedAmount, len(bankTxs))
+	for _, tx := range bankTxs {
+		converted, err := uc.toReporting(ctx, tx.NormalizedAmount, tx.Currency, tx.Date)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bank transaction %s: %w", tx.UniqueIdentifier, err)
+		}
+		bankAmounts[bankKeyOf(tx)] = converted
+	}
+	return systemAmounts, bankAmounts, nil
+}
@@ -0,0 +1,367 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Confidence of matches on type, amount and date.
+const (
+	exactConfidence     = 0.9 // the only candidates on both sides
+	groupConfidence     = 0.8 // equally sized groups paired in order
+	ambiguousConfidence = 0.5 // picked out of a larger group
+	hintedConfidence    = 0.7 // picked out of a larger group, the bank description mentioning the system ID
+)
+
+// dateGroupMatcher groups unmatched transactions by type and reporting amount, then by
+// booking date. A system date group matches the bank date group of equal size closest to
+// it within the settlement window, pairing transactions in date, amount and ID order.
+type dateGroupMatcher struct {
+	name  string
+	multi bool // match groups of more than one transaction instead of single transactions
+	phase groupPhase
+}
+
+// groupPhase restricts group matching to equally or unequally sized groups.
+type groupPhase int
+
+const (
+	bothPhases groupPhase = iota
+	equalPhase
+	unequalPhase
+)
+
+// ExactMatcher pairs a system and a bank transaction with the same type and amount when
+// each is the only such transaction on its date.
+func ExactMatcher() Matcher {
+	return dateGroupMatcher{name: "exact"}
+}
+
+// GroupMatcher pairs groups of system and bank transactions that share a type, an amount
+// and a date. When the groups differ in size, the smaller one is matched in full and its
+// pairs are reported as ambiguous matches.
+func GroupMatcher() Matcher {
+	return dateGroupMatcher{name: "group", multi: true}
+}
+
+func (m dateGroupMatcher) Name() string {
+	return m.name
+}
+
+// phases splits group matching into pairing the equally sized groups of every date, then
+// the unequally sized ones.
+func (m dateGroupMatcher) phases() []Matcher {
+	if !m.multi || m.phase != bothPhases {
+		return []Matcher{m}
+	}
+	equal, unequal := m, m
+	equal.phase, unequal.phase = equalPhase, unequalPhase
+	return []Matcher{equal, unequal}
+}
+
+func (m dateGroupMatcher) Match(ctx context.Context, state *MatchState) error {
+	systemMap := make(map[string]map[time.Time][]domain.SystemTransaction)
+	bankMap := make(map[string]map[time.Time][]domain.BankTransaction)
+
+	for _, sysTx := range state.UnmatchedSystem() {
+		key := buildGroupKey(sysTx.Type, state.SystemAmount(sysTx))
+		if systemMap[key] == nil {
+			systemMap[key] = make(map[time.Time][]domain.SystemTransaction)
+		}
+		day := dayOf(sysTx.TransactionTime)
+		systemMap[key][day] = append(systemMap[key][day], sysTx)
+	}
+	for _, bankTx := range state.UnmatchedBank() {
+		key := buildGroupKey(bankTx.Type, state.BankAmount(bankTx))
+		if bankMap[key] == nil {
+			bankMap[key] = make(map[time.Time][]domain.BankTransaction)
+		}
+		day := dayOf(bankTx.Date)
+		bankMap[key][day] = append(bankMap[key][day], bankTx)
+	}
+
+	keys := make([]string, 0, len(systemMap))
+	for key := range systemMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		sysByDay, bankByDay := systemMap[key], bankMap[key]
+		if m.phase == unequalPhase {
+			matchUnequalGroups(state, key, sysByDay, bankByDay)
+			continue
+		}
+		for _, sysDay := range sortedDays(sysByDay) {
+			sysTxs := sysByDay[sysDay]
+			if (len(sysTxs) > 1) != m.multi {
+				continue
+			}
+			bankDay, ok := state.settlement.closestDate(sysDay, sortedDays(bankByDay), func(d time.Time) bool {
+				return len(bankByDay[d]) == len(sysTxs)
+			})
+			if !ok {
+				continue
+			}
+			bankTxs := bankByDay[bankDay]
+			ev := Evidence{Key: dayKey(key, sysDay), Confidence: exactConfidence}
+			if m.multi {
+				ev.Confidence = groupConfidence
+			}
+			for i := 0; i < len(sysTxs); i++ {
+				state.Match(sysTxs[i], bankTxs[i], ev)
+			}
+			// Both groups are used up
+			delete(sysByDay, sysDay)
+			delete(bankByDay, bankDay)
+		}
+
+		if m.multi && m.phase == bothPhases {
+			matchUnequalGroups(state, key, sysByDay, bankByDay)
+		}
+	}
+	return nil
+}
+
+// matchUnequalGroups pairs the remaining date groups of one type and amount whose sizes
+// differ, e.g. three system debits of 75.00 against two bank debits of 75.00 on a day.
+// The smaller side is matched in full and the surplus stays unmatched. Which transactions
+// pair up is a guess, so the pairs are flagged as ambiguous.
+func matchUnequalGroups(state *MatchState, key string, sysByDay map[time.Time][]domain.SystemTransaction, bankByDay map[time.Time][]domain.BankTransaction) {
+	for _, sysDay := range sortedDays(sysByDay) {
+		sysTxs := sysByDay[sysDay]
+		bankDay, ok := state.settlement.closestDate(sysDay, sortedDays(bankByDay), func(d time.Time) bool {
+			return len(bankByDay[d]) > 0 && (len(sysTxs) > 1 || len(bankByDay[d]) > 1)
+		})
+		if !ok {
+			continue
+		}
+
+		bankTxs := bankByDay[bankDay]
+		paired := make([]bool, len(bankTxs))
+		for _, p := range rankPairs(sysTxs, bankTxs) {
+			ev := Evidence{Key: dayKey(key, sysDay), Confidence: ambiguousConfidence}
+			if p.hinted {
+				ev.Confidence = hintedConfidence
+			}
+			state.MatchAmbiguous(sysTxs[p.sys], bankTxs[p.bank], ev)
+			paired[p.bank] = true
+		}
+
+		var rest []domain.BankTransaction
+		for i, bankTx := range bankTxs {
+			if !paired[i] {
+				rest = append(rest, bankTx)
+			}
+		}
+		bankByDay[bankDay] = rest
+	}
+}
+
+// groupPair is a candidate pairing of the system and bank transactions at the given indexes.
+type groupPair struct {
+	sys, bank int
+	hinted    bool          // the bank description mentions the system transaction ID
+	distance  time.Duration // between the system timestamp and the bank booking time
+}
+
+// maxPairCandidates bounds how many transactions of the larger side each transaction of
+// the smaller side is ranked against by timestamp, the nearest ones, so that days with many
+// equal amounts (payroll, fixed fees) are not paired in quadratic time.
+const maxPairCandidates = 8
+
+// rankPairs pairs every transaction of the smaller side with one of the larger side,
+// preferring bank descriptions that mention the system transaction ID, then the nearest
+// timestamps, then date, amount and ID order.
+func rankPairs(sysTxs []domain.SystemTransaction, bankTxs []domain.BankTransaction) []groupPair {
+	candidates := hintedPairs(sysTxs, bankTxs)
+	sysTimes := make([]time.Time, len(sysTxs))
+	for i, sysTx := range sysTxs {
+		sysTimes[i] = sysTx.TransactionTime
+	}
+	bankTimes := make([]time.Time, len(bankTxs))
+	for j, bankTx := range bankTxs {
+		bankTimes[j] = bankTx.Date
+	}
+	if len(sysTxs) <= len(bankTxs) {
+		index := newTimeIndex(bankTimes)
+		for i, t := range sysTimes {
+			for _, j := range index.nearest(t, maxPairCandidates) {
+				candidates = append(candidates, groupPair{sys: i, bank: j, distance: absDuration(bankTimes[j].Sub(t))})
+			}
+		}
+	} else {
+		index := newTimeIndex(sysTimes)
+		for j, t := range bankTimes {
+			for _, i := range index.nearest(t, maxPairCandidates) {
+				candidates = append(candidates, groupPair{sys: i, bank: j, distance: absDuration(t.Sub(sysTimes[i]))})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.hinted != b.hinted {
+			return a.hinted
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.sys != b.sys {
+			return a.sys < b.sys
+		}
+		return a.bank < b.bank
+	})
+
+	usedSys := make([]bool, len(sysTxs))
+	usedBank := make([]bool, len(bankTxs))
+	var pairs []groupPair
+	for _, c := range candidates {
+		if usedSys[c.sys] || usedBank[c.bank] {
+			continue
+		}
+		usedSys[c.sys], usedBank[c.bank] = true, true
+		pairs = append(pairs, c)
+	}
+
+	// Transactions whose nearest candidates were all taken pair up with those left over on
+	// the other side, in order
+	for i, j := 0, 0; i < len(sysTxs) && j < len(bankTxs); {
+		switch {
+		case usedSys[i]:
+			i++
+		case usedBank[j]:
+			j++
+		default:
+			pairs = append(pairs, groupPair{sys: i, bank: j, distance: absDuration(bankTimes[j].Sub(sysTimes[i]))})
+			usedSys[i], usedBank[j] = true, true
+		}
+	}
+	return pairs
+}
+
+// hintedPairs returns the pairs whose bank description mentions the system transaction ID,
+// looking up the IDs in an index of the descriptions' words rather than searching every
+// description for every ID.
+func hintedPairs(sysTxs []domain.SystemTransaction, bankTxs []domain.BankTransaction) []groupPair {
+	byWord := make(map[string][]int)
+	for j, bankTx := range bankTxs {
+		for _, word := range words(bankTx.Description) {
+			if banks := byWord[word]; len(banks) == 0 || banks[len(banks)-1] != j {
+				byWord[word] = append(banks, j)
+			}
+		}
+	}
+
+	var pairs []groupPair
+	for i, sysTx := range sysTxs {
+		idWords := words(sysTx.TrxID)
+		if len(idWords) == 0 {
+			continue
+		}
+		for _, j := range byWord[idWords[0]] {
+			if mentions(bankTxs[j].Description, sysTx.TrxID) {
+				pairs = append(pairs, groupPair{sys: i, bank: j, hinted: true, distance: absDuration(bankTxs[j].Date.Sub(sysTx.TransactionTime))})
+			}
+		}
+	}
+	return pairs
+}
+
+// words splits text into its upper-cased runs of letters and digits.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToUpper(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// mentions reports whether text contains id as a whole word, ignoring case: not preceded
+// or followed by a letter or digit, so that a description mentioning SYS10 does not
+// mention SYS1.
+func mentions(text, id string) bool {
+	text, id = strings.ToUpper(text), strings.ToUpper(id)
+	for offset := 0; ; {
+		k := strings.Index(text[offset:], id)
+		if k < 0 {
+			return false
+		}
+		start, end := offset+k, offset+k+len(id)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// timeIndex orders timestamps to find those nearest to a time.
+type timeIndex struct {
+	times    []time.Time
+	order    []int // indexes of times in chronological order, equal times in index order
+	runStart []int // for each position in order, the first position holding the same time
+}
+
+func newTimeIndex(times []time.Time) timeIndex {
+	idx := timeIndex{times: times, order: make([]int, len(times)), runStart: make([]int, len(times))}
+	for i := range idx.order {
+		idx.order[i] = i
+	}
+	sort.SliceStable(idx.order, func(a, b int) bool { return times[idx.order[a]].Before(times[idx.order[b]]) })
+	for k := range idx.order {
+		if k > 0 && times[idx.order[k]].Equal(times[idx.order[k-1]]) {
+			idx.runStart[k] = idx.runStart[k-1]
+		} else {
+			idx.runStart[k] = k
+		}
+	}
+	return idx
+}
+
+// nearest returns the indexes of at most n times nearest to t. Of equal times, the first
+// listed are returned first.
+func (idx timeIndex) nearest(t time.Time, n int) []int {
+	hi := sort.Search(len(idx.order), func(k int) bool { return !idx.times[idx.order[k]].Before(t) })
+	lo := hi - 1
+	nearest := make([]int, 0, n)
+	for len(nearest) < n && (lo >= 0 || hi < len(idx.order)) {
+		if hi >= len(idx.order) || (lo >= 0 && t.Sub(idx.times[idx.order[lo]]) <= idx.times[idx.order[hi]].Sub(t)) {
+			// The times before t are walked back a run of equal times at a time
+			start := idx.runStart[lo]
+			for k := start; k <= lo && len(nearest) < n; k++ {
+				nearest = append(nearest, idx.order[k])
+			}
+			lo = start - 1
+		} else {
+			nearest = append(nearest, idx.order[hi])
+			hi++
+		}
+	}
+	return nearest
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func buildGroupKey(txType domain.TransactionType, amount domain.Decimal) string {
+	return fmt.Sprintf("%s-%s", txType, amount)
+}
+
+// dayKey is the key a date group was matched on, e.g. "DEBIT-75@2025-09-01".
+func dayKey(groupKey string, day time.Time) string {
+	return groupKey + "@" + day.Format(time.DateOnly)
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"time"
+
 	"mini-reconciliation/internal/domain"
 )
 
@@ -10,6 +12,22 @@ import (
 //
 //go:generate mockgen -destination=mocks/mock_repository.go -source=interface.go TransactionRepository
 type TransactionRepository interface {
-	GetSystemTransactions(ctx context.Context, path string) ([]domain.SystemTransaction, error)
-	GetBankTransactions(ctx context.Context, paths []string) ([]domain.BankTransaction, error)
+	GetSystemTransactions(ctx context.Context, source domain.Source) ([]domain.SystemTransaction, error)
+	GetBankTransactions(ctx context.Context, sources []domain.Source) ([]domain.BankTransaction, error)
+	// GetBankBalances returns the opening and closing balances printed on the bank statements.
+	GetBankBalances(ctx context.Context, sources []domain.Source) ([]domain.StatementBalance, error)
+}
+
+// TransactionStreamer reads transactions without holding them all in memory. The streams
+// yield the transactions dated from start to the end of end's day, ordered by the calendar
+// date they are recorded on.
+type TransactionStreamer interface {
+	StreamSystemTransactions(ctx context.Context, source domain.Source, start, end time.Time) (domain.SystemTransactionStream, error)
+	StreamBankTransactions(ctx context.Context, sources []domain.Source, start, end time.Time) (domain.BankTransactionStream, error)
+}
+
+// IngestionErrorSource reports the input rows a lenient repository skipped because they
+// could not be read.
+type IngestionErrorSource interface {
+	IngestionErrors() []domain.IngestionError
 }
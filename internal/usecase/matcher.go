@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Matcher is one pass of the matching pipeline. Each matcher looks at the transactions
+// still unmatched in the state and records the pairs it finds through the state, so later
+// matchers only see what earlier ones left behind.
+//
+// The built-in passes are ReferenceMatcher, ExactMatcher, GroupMatcher, AggregateMatcher
+// and ToleranceMatcher; bank-specific matchers can be added to the pipeline with WithMatchers.
+type Matcher interface {
+	// Name identifies the pass in errors and reports, e.g. "reference".
+	Name() string
+	// Match pairs unmatched transactions in state.
+	Match(ctx context.Context, state *MatchState) error
+}
+
+// Evidence explains why a matcher paired transactions. It is listed in the report's
+// matched_transactions section when match explanations are enabled.
+type Evidence struct {
+	// Key is what the transactions were matched on, e.g. the quoted reference "SYS001"
+	// or the type, amount and date "DEBIT-75@2025-09-01".
+	Key string
+	// Confidence scores the match from 0 (a guess) to 1 (certain).
+	Confidence float64
+	// Reason is reported when the matched amounts differ. It defaults to amount_mismatch.
+	Reason domain.DiscrepancyReason
+}
+
+// MatchState holds the transactions under reconciliation and the matches found so far.
+// Amounts exposed by the state are in the reporting currency.
+type MatchState struct {
+	systemTxs         []domain.SystemTransaction
+	bankTxs           []domain.BankTransaction
+	systemAmounts     map[string]convertedAmount
+	bankAmounts       map[bankKey]convertedAmount
+	matchedSystem     map[string]bool
+	matchedBank       map[bankKey]bool
+	reportingCurrency domain.Currency
+	settlement        settlementWindow
+	report            *domain.ReconciliationReport
+	pass              string // name of the running matcher
+	explain           bool   // record Evidence under report.MatchedTransactions
+}
+
+// bankKey identifies a bank transaction. Unique identifiers are only unique within a
+// statement, so two statements may both have a transaction "1".
+type bankKey struct {
+	source string
+	id     string
+}
+
+func bankKeyOf(tx domain.BankTransaction) bankKey {
+	return bankKey{source: tx.BankSource, id: tx.UniqueIdentifier}
+}
+
+// UnmatchedSystem returns the system transactions not matched yet, ordered by date, amount and ID.
+func (s *MatchState) UnmatchedSystem() []domain.SystemTransaction {
+	var unmatched []domain.SystemTransaction
+	for _, tx := range s.systemTxs {
+		if !s.matchedSystem[tx.TrxID] {
+			unmatched = append(unmatched, tx)
+		}
+	}
+	return unmatched
+}
+
+// UnmatchedBank returns the bank transactions not matched yet, ordered by date, amount and ID.
+func (s *MatchState) UnmatchedBank() []domain.BankTransaction {
+	var unmatched []domain.BankTransaction
+	for _, tx := range s.bankTxs {
+		if !s.matchedBank[bankKeyOf(tx)] {
+			unmatched = append(unmatched, tx)
+		}
+	}
+	return unmatched
+}
+
+// IsSystemMatched reports whether the system transaction has been matched.
+func (s *MatchState) IsSystemMatched(tx domain.SystemTransaction) bool {
+	return s.matchedSystem[tx.TrxID]
+}
+
+// IsBankMatched reports whether the bank transaction has been matched.
+func (s *MatchState) IsBankMatched(tx domain.BankTransaction) bool {
+	return s.matchedBank[bankKeyOf(tx)]
+}
+
+// SystemAmount returns the amount of a system transaction in the reporting currency.
+func (s *MatchState) SystemAmount(tx domain.SystemTransaction) domain.Decimal {
+	return s.systemAmounts[tx.TrxID].amount
+}
+
+// BankAmount returns the normalized amount of a bank transaction in the reporting currency.
+func (s *MatchState) BankAmount(tx domain.BankTransaction) domain.Decimal {
+	return s.bankAmounts[bankKeyOf(tx)].amount
+}
+
+// ReportingCurrency returns the currency amounts are compared in.
+func (s *MatchState) ReportingCurrency() domain.Currency {
+	return s.reportingCurrency
+}
+
+// WithinSettlementWindow reports whether a bank booking on bankDate may settle a system
+// transaction made at sysTime, given the configured settlement lag.
+func (s *MatchState) WithinSettlementWindow(sysTime, bankDate time.Time) bool {
+	return s.settlement.contains(sysTime, bankDate)
+}
+
+// Match records a one-to-one match. If the amounts differ the pair is reported as a
+// discrepancy with the evidence's reason. Pairs in the same currency are compared as-is;
+// otherwise their reporting amounts are compared.
+func (s *MatchState) Match(sysTx domain.SystemTransaction, bankTx domain.BankTransaction, ev Evidence) {
+	s.match(sysTx, bankTx, ev, matchAutomatic)
+}
+
+// MatchAmbiguous records a one-to-one match like Match, flagging it as picked from several
+// equally valid candidates. Such pairs are also listed under ambiguous_matches.
+func (s *MatchState) MatchAmbiguous(sysTx domain.SystemTransaction, bankTx domain.BankTransaction, ev Evidence) {
+	s.match(sysTx, bankTx, ev, matchAmbiguous)
+}
+
+// matchKind flags how certain a one-to-one match is.
+type matchKind int
+
+const (
+	matchAutomatic matchKind = iota
+	matchAmbiguous           // picked out of several equally valid candidates
+	matchManual              // decided by an override
+)
+
+func (s *MatchState) match(sysTx domain.SystemTransaction, bankTx domain.BankTransaction, ev Evidence, kind matchKind) {
+	s.matchedSystem[sysTx.TrxID] = true
+	s.matchedBank[bankKeyOf(bankTx)] = true
+
+	report := s.report
+	report.ReconciliationSummary.MatchedTransactions++
+	sysAmount := s.systemAmounts[sysTx.TrxID]
+	bankAmount := s.bankAmounts[bankKeyOf(bankTx)]
+
+	pair := matchedPair(sysTx, bankTx)
+	pair.Ambiguous = kind == matchAmbiguous
+	pair.Manual = kind == matchManual
+	offset := pair.DayOffset
+	if offset != 0 {
+		report.LaggedMatches = append(report.LaggedMatches, pair)
+	}
+	switch kind {
+	case matchAmbiguous:
+		report.AmbiguousMatches = append(report.AmbiguousMatches, pair)
+	case matchManual:
+		report.ManualMatches = append(report.ManualMatches, pair)
+	}
+	s.record(pair, ev)
+
+	reason := ev.Reason
+	if reason == "" {
+		reason = domain.DiscrepancyAmountMismatch
+	}
+
+	// Amounts are exact decimals, so any difference is a discrepancy
+	var discrepant bool
+	if domain.ParseCurrency(string(sysTx.Currency)) == domain.ParseCurrency(string(bankTx.Currency)) {
+		discrepant = !sysTx.Amount.Equal(bankTx.NormalizedAmount)
+	} else {
+		discrepant = !sysAmount.amount.Equal(bankAmount.amount)
+	}
+	if discrepant {
+		diff := sysAmount.amount.Sub(bankAmount.amount)
+		report.DiscrepantTransactions.Count++
+		report.DiscrepantTransactions.TotalDiscrepancyValue = report.DiscrepantTransactions.TotalDiscrepancyValue.Add(diff.Abs())
+		report.DiscrepantTransactions.Details = append(report.DiscrepantTransactions.Details, domain.DiscrepancyDetail{
+			SystemTransaction: sysTx,
+			BankTransaction:   bankTx,
+			Reason:            reason,
+			Difference:        diff,
+			DayOffset:         offset,
+			SystemFX:          sysAmount.fx,
+			BankFX:            bankAmount.fx,
+		})
+	}
+}
+
+// MatchGroup records a match between several system and bank transactions with the
+// same total, reported under grouped_matches.
+func (s *MatchState) MatchGroup(sysTxs []domain.SystemTransaction, bankTxs []domain.BankTransaction, ev Evidence) {
+	group := domain.GroupedMatch{
+		SystemTransactions: sysTxs,
+		BankTransactions:   bankTxs,
+		Currency:           s.reportingCurrency,
+	}
+	for _, tx := range sysTxs {
+		s.matchedSystem[tx.TrxID] = true
+		group.Total = group.Total.Add(s.SystemAmount(tx))
+	}
+	for _, tx := range bankTxs {
+		s.matchedBank[bankKeyOf(tx)] = true
+	}
+	for _, sysTx := range sysTxs {
+		for _, bankTx := range bankTxs {
+			s.record(matchedPair(sysTx, bankTx), ev)
+		}
+	}
+
+	s.report.ReconciliationSummary.GroupedSystemTransactions += len(sysTxs)
+	s.report.ReconciliationSummary.GroupedBankTransactions += len(bankTxs)
+	s.report.GroupedMatches.Count++
+	s.report.GroupedMatches.Details = append(s.report.GroupedMatches.Details, group)
+}
+
+// ignoreSystem excludes a system transaction from matching and from the unmatched list.
+func (s *MatchState) ignoreSystem(tx domain.SystemTransaction) {
+	s.matchedSystem[tx.TrxID] = true
+	ignored := s.ignored()
+	ignored.Count++
+	ignored.SystemTransactions = append(ignored.SystemTransactions, tx)
+}
+
+// ignoreBank excludes a bank transaction from matching and from the unmatched list.
+func (s *MatchState) ignoreBank(tx domain.BankTransaction) {
+	s.matchedBank[bankKeyOf(tx)] = true
+	ignored := s.ignored()
+	ignored.Count++
+	ignored.BankTransactions = append(ignored.BankTransactions, tx)
+}
+
+func (s *MatchState) ignored() *domain.IgnoredTransactions {
+	if s.report.IgnoredTransactions == nil {
+		s.report.IgnoredTransactions = &domain.IgnoredTransactions{
+			SystemTransactions: make([]domain.SystemTransaction, 0),
+			BankTransactions:   make([]domain.BankTransaction, 0),
+		}
+	}
+	return s.report.IgnoredTransactions
+}
+
+// record lists the pair and the evidence for it under matched_transactions.
+func (s *MatchState) record(pair domain.MatchedPair, ev Evidence) {
+	if !s.explain {
+		return
+	}
+	matched := s.report.MatchedTransactions
+	matched.Count++
+	matched.Details = append(matched.Details, domain.MatchExplanation{
+		MatchedPair: pair,
+		Pass:        s.pass,
+		Key:         ev.Key,
+		Confidence:  ev.Confidence,
+	})
+}
+
+func matchedPair(sysTx domain.SystemTransaction, bankTx domain.BankTransaction) domain.MatchedPair {
+	return domain.MatchedPair{
+		SystemTrxID:          sysTx.TrxID,
+		BankUniqueIdentifier: bankTx.UniqueIdentifier,
+		BankSource:           bankTx.BankSource,
+		SystemDate:           sysTx.TransactionTime.Format(time.DateOnly),
+		BankDate:             bankTx.Date.Format(time.DateOnly),
+		DayOffset:            dayOffset(sysTx.TransactionTime, bankTx.Date),
+	}
+}
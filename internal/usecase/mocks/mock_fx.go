@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: fx.go
+
+// Package mock_usecase is a generated GoMock package.
+package mock_usecase
+
+import (
+	context "context"
+	domain "mini-reconciliation/internal/domain"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFXRateProvider is a mock of FXRateProvider interface.
+type MockFXRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockFXRateProviderMockRecorder
+}
+
+// MockFXRateProviderMockRecorder is the mock recorder for MockFXRateProvider.
+type MockFXRateProviderMockRecorder struct {
+	mock *MockFXRateProvider
+}
+
+// NewMockFXRateProvider creates a new mock instance.
+func NewMockFXRateProvider(ctrl *gomock.Controller) *MockFXRateProvider {
+	mock := &MockFXRateProvider{ctrl: ctrl}
+	mock.recorder = &MockFXRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFXRateProvider) EXPECT() *MockFXRateProviderMockRecorder {
+	return m.recorder
+}
+
+// Rate mocks base method.
+func (m *MockFXRateProvider) Rate(ctx context.Context, from, to domain.Currency, date time.Time) (domain.FXRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rate", ctx, from, to, date)
+	ret0, _ := ret[0].(domain.FXRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rate indicates an expected call of Rate.
+func (mr *MockFXRateProviderMockRecorder) Rate(ctx, from, to, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rate", reflect.TypeOf((*MockFXRateProvider)(nil).Rate), ctx, from, to, date)
+}
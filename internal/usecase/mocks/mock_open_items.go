@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: open_items.go
+
+// Package mock_usecase is a generated GoMock package.
+package mock_usecase
+
+import (
+	context "context"
+	domain "mini-reconciliation/internal/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOpenItemStore is a mock of OpenItemStore interface.
+type MockOpenItemStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOpenItemStoreMockRecorder
+}
+
+// MockOpenItemStoreMockRecorder is the mock recorder for MockOpenItemStore.
+type MockOpenItemStoreMockRecorder struct {
+	mock *MockOpenItemStore
+}
+
+// NewMockOpenItemStore creates a new mock instance.
+func NewMockOpenItemStore(ctrl *gomock.Controller) *MockOpenItemStore {
+	mock := &MockOpenItemStore{ctrl: ctrl}
+	mock.recorder = &MockOpenItemStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOpenItemStore) EXPECT() *MockOpenItemStoreMockRecorder {
+	return m.recorder
+}
+
+// Load mocks base method.
+func (m *MockOpenItemStore) Load(ctx context.Context) (domain.OpenItems, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", ctx)
+	ret0, _ := ret[0].(domain.OpenItems)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load.
+func (mr *MockOpenItemStoreMockRecorder) Load(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockOpenItemStore)(nil).Load), ctx)
+}
+
+// Save mocks base method.
+func (m *MockOpenItemStore) Save(ctx context.Context, items domain.OpenItems) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockOpenItemStoreMockRecorder) Save(ctx, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockOpenItemStore)(nil).Save), ctx, items)
+}
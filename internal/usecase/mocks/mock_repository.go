@@ -8,6 +8,7 @@ import (
 	context "context"
 	domain "mini-reconciliation/internal/domain"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,32 +36,137 @@ func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecor
 	return m.recorder
 }
 
+// GetBankBalances mocks base method.
+func (m *MockTransactionRepository) GetBankBalances(ctx context.Context, sources []domain.Source) ([]domain.StatementBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankBalances", ctx, sources)
+	ret0, _ := ret[0].([]domain.StatementBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBankBalances indicates an expected call of GetBankBalances.
+func (mr *MockTransactionRepositoryMockRecorder) GetBankBalances(ctx, sources interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankBalances", reflect.TypeOf((*MockTransactionRepository)(nil).GetBankBalances), ctx, sources)
+}
+
 // GetBankTransactions mocks base method.
-func (m *MockTransactionRepository) GetBankTransactions(ctx context.Context, paths []string) ([]domain.BankTransaction, error) {
+func (m *MockTransactionRepository) GetBankTransactions(ctx context.Context, sources []domain.Source) ([]domain.BankTransaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBankTransactions", ctx, paths)
+	ret := m.ctrl.Call(m, "GetBankTransactions", ctx, sources)
 	ret0, _ := ret[0].([]domain.BankTransaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBankTransactions indicates an expected call of GetBankTransactions.
-func (mr *MockTransactionRepositoryMockRecorder) GetBankTransactions(ctx, paths interface{}) *gomock.Call {
+func (mr *MockTransactionRepositoryMockRecorder) GetBankTransactions(ctx, sources interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetBankTransactions), ctx, paths)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetBankTransactions), ctx, sources)
 }
 
 // GetSystemTransactions mocks base method.
-func (m *MockTransactionRepository) GetSystemTransactions(ctx context.Context, path string) ([]domain.SystemTransaction, error) {
+func (m *MockTransactionRepository) GetSystemTransactions(ctx context.Context, source domain.Source) ([]domain.SystemTransaction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSystemTransactions", ctx, path)
+	ret := m.ctrl.Call(m, "GetSystemTransactions", ctx, source)
 	ret0, _ := ret[0].([]domain.SystemTransaction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSystemTransactions indicates an expected call of GetSystemTransactions.
-func (mr *MockTransactionRepositoryMockRecorder) GetSystemTransactions(ctx, path interface{}) *gomock.Call {
+func (mr *MockTransactionRepositoryMockRecorder) GetSystemTransactions(ctx, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetSystemTransactions), ctx, source)
+}
+
+// MockTransactionStreamer is a mock of TransactionStreamer interface.
+type MockTransactionStreamer struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionStreamerMockRecorder
+}
+
+// MockTransactionStreamerMockRecorder is the mock recorder for MockTransactionStreamer.
+type MockTransactionStreamerMockRecorder struct {
+	mock *MockTransactionStreamer
+}
+
+// NewMockTransactionStreamer creates a new mock instance.
+func NewMockTransactionStreamer(ctrl *gomock.Controller) *MockTransactionStreamer {
+	mock := &MockTransactionStreamer{ctrl: ctrl}
+	mock.recorder = &MockTransactionStreamerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionStreamer) EXPECT() *MockTransactionStreamerMockRecorder {
+	return m.recorder
+}
+
+// StreamBankTransactions mocks base method.
+func (m *MockTransactionStreamer) StreamBankTransactions(ctx context.Context, sources []domain.Source, start, end time.Time) (domain.BankTransactionStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamBankTransactions", ctx, sources, start, end)
+	ret0, _ := ret[0].(domain.BankTransactionStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamBankTransactions indicates an expected call of StreamBankTransactions.
+func (mr *MockTransactionStreamerMockRecorder) StreamBankTransactions(ctx, sources, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamBankTransactions", reflect.TypeOf((*MockTransactionStreamer)(nil).StreamBankTransactions), ctx, sources, start, end)
+}
+
+// StreamSystemTransactions mocks base method.
+func (m *MockTransactionStreamer) StreamSystemTransactions(ctx context.Context, source domain.Source, start, end time.Time) (domain.SystemTransactionStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSystemTransactions", ctx, source, start, end)
+	ret0, _ := ret[0].(domain.SystemTransactionStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamSystemTransactions indicates an expected call of StreamSystemTransactions.
+func (mr *MockTransactionStreamerMockRecorder) StreamSystemTransactions(ctx, source, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSystemTransactions", reflect.TypeOf((*MockTransactionStreamer)(nil).StreamSystemTransactions), ctx, source, start, end)
+}
+
+// MockIngestionErrorSource is a mock of IngestionErrorSource interface.
+type MockIngestionErrorSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestionErrorSourceMockRecorder
+}
+
+// MockIngestionErrorSourceMockRecorder is the mock recorder for MockIngestionErrorSource.
+type MockIngestionErrorSourceMockRecorder struct {
+	mock *MockIngestionErrorSource
+}
+
+// NewMockIngestionErrorSource creates a new mock instance.
+func NewMockIngestionErrorSource(ctrl *gomock.Controller) *MockIngestionErrorSource {
+	mock := &MockIngestionErrorSource{ctrl: ctrl}
+	mock.recorder = &MockIngestionErrorSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestionErrorSource) EXPECT() *MockIngestionErrorSourceMockRecorder {
+	return m.recorder
+}
+
+// IngestionErrors mocks base method.
+func (m *MockIngestionErrorSource) IngestionErrors() []domain.IngestionError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IngestionErrors")
+	ret0, _ := ret[0].([]domain.IngestionError)
+	return ret0
+}
+
+// IngestionErrors indicates an expected call of IngestionErrors.
+func (mr *MockIngestionErrorSourceMockRecorder) IngestionErrors() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).GetSystemTransactions), ctx, path)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IngestionErrors", reflect.TypeOf((*MockIngestionErrorSource)(nil).IngestionErrors))
 }
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: runs.go
+
+// Package mock_usecase is a generated GoMock package.
+package mock_usecase
+
+import (
+	context "context"
+	domain "mini-reconciliation/internal/domain"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRunStore is a mock of RunStore interface.
+type MockRunStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRunStoreMockRecorder
+}
+
+// MockRunStoreMockRecorder is the mock recorder for MockRunStore.
+type MockRunStoreMockRecorder struct {
+	mock *MockRunStore
+}
+
+// NewMockRunStore creates a new mock instance.
+func NewMockRunStore(ctrl *gomock.Controller) *MockRunStore {
+	mock := &MockRunStore{ctrl: ctrl}
+	mock.recorder = &MockRunStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRunStore) EXPECT() *MockRunStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateRun mocks base method.
+func (m *MockRunStore) CreateRun(ctx context.Context, run domain.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRun indicates an expected call of CreateRun.
+func (mr *MockRunStoreMockRecorder) CreateRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRun", reflect.TypeOf((*MockRunStore)(nil).CreateRun), ctx, run)
+}
+
+// GetReport mocks base method.
+func (m *MockRunStore) GetReport(ctx context.Context, id string) (*domain.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", ctx, id)
+	ret0, _ := ret[0].(*domain.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport.
+func (mr *MockRunStoreMockRecorder) GetReport(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockRunStore)(nil).GetReport), ctx, id)
+}
+
+// GetRun mocks base method.
+func (m *MockRunStore) GetRun(ctx context.Context, id string) (domain.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRun", ctx, id)
+	ret0, _ := ret[0].(domain.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRun indicates an expected call of GetRun.
+func (mr *MockRunStoreMockRecorder) GetRun(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRun", reflect.TypeOf((*MockRunStore)(nil).GetRun), ctx, id)
+}
+
+// ListRuns mocks base method.
+func (m *MockRunStore) ListRuns(ctx context.Context, limit int) ([]domain.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuns", ctx, limit)
+	ret0, _ := ret[0].([]domain.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuns indicates an expected call of ListRuns.
+func (mr *MockRunStoreMockRecorder) ListRuns(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuns", reflect.TypeOf((*MockRunStore)(nil).ListRuns), ctx, limit)
+}
+
+// UpdateRun mocks base method.
+func (m *MockRunStore) UpdateRun(ctx context.Context, run domain.Run, report *domain.ReconciliationReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRun", ctx, run, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRun indicates an expected call of UpdateRun.
+func (mr *MockRunStoreMockRecorder) UpdateRun(ctx, run, report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRun", reflect.TypeOf((*MockRunStore)(nil).UpdateRun), ctx, run, report)
+}
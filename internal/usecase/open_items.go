@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// OpenItemStore persists the transactions left unmatched at the end of a reconciliation
+// period, so that the next period can still match them.
+//
+//go:generate mockgen -destination=mocks/mock_open_items.go -source=open_items.go OpenItemStore
+type OpenItemStore interface {
+	// Load returns the open items saved by the previous run, or none on the first run.
+	Load(ctx context.Context) (domain.OpenItems, error)
+	// Save replaces the stored open items.
+	Save(ctx context.Context, items domain.OpenItems) error
+}
+
+// carriedItems records when each open item brought in from earlier periods was first left
+// open, keyed by system TrxID and bank statement and UniqueIdentifier.
+type carriedItems struct {
+	system map[string]string
+	bank   map[bankKey]string
+}
+
+// carryIn adds the stored open items to the transactions of the period. Items that are
+// also in the period's own files (e.g. when a period is reconciled again) are skipped.
+// Items dated before the period are matched as if dated on its first date; see
+// settlementWindow.acrossPeriods.
+func (uc *ReconciliationUseCase) carryIn(ctx context.Context, systemTxs []domain.SystemTransaction, bankTxs []domain.BankTransaction) ([]domain.SystemTransaction, []domain.BankTransaction, carriedItems, error) {
+	carried := carriedItems{system: make(map[string]string), bank: make(map[bankKey]string)}
+	stored, err := uc.openItems.Load(ctx)
+	if err != nil {
+		return nil, nil, carried, err
+	}
+
+	presentSystem := make(map[string]bool, len(systemTxs))
+	for _, tx := range systemTxs {
+		presentSystem[tx.TrxID] = true
+	}
+	presentBank := make(map[bankKey]bool, len(bankTxs))
+	for _, tx := range bankTxs {
+		presentBank[bankKeyOf(tx)] = true
+	}
+
+	for _, item := range stored.Items {
+		switch {
+		case item.System != nil && !presentSystem[item.System.TrxID]:
+			systemTxs = append(systemTxs, *item.System)
+			carried.system[item.System.TrxID] = item.FirstSeen
+		case item.Bank != nil && !presentBank[bankKeyOf(*item.Bank)]:
+			bankTxs = append(bankTxs, item.BankTransaction())
+			carried.bank[bankKeyOf(*item.Bank)] = item.FirstSeen
+		}
+	}
+	return systemTxs, bankTxs, carried, nil
+}
+
+// carryOut saves the transactions still unmatched at the end of the period as open items
+// and reports their age.
+func (uc *ReconciliationUseCase) carryOut(ctx context.Context, report *domain.ReconciliationReport, unmatchedSystem []domain.SystemTransaction, unmatchedBank []domain.BankTransaction, carried carriedItems, end time.Time) error {
+	asOf := end.Format(time.DateOnly)
+	openItems := &domain.OpenItemsReport{
+		CarriedIn: len(carried.system) + len(carried.bank),
+		Open:      make([]domain.OpenItemAge, 0),
+	}
+	stored := domain.OpenItems{AsOf: asOf, Items: make([]domain.OpenItem, 0)}
+
+	for _, tx := range unmatchedSystem {
+		tx := tx
+		firstSeen, isCarried := carried.system[tx.TrxID]
+		if !isCarried {
+			firstSeen = asOf
+		}
+		stored.Items = append(stored.Items, domain.OpenItem{System: &tx, FirstSeen: firstSeen})
+		openItems.Open = append(openItems.Open, domain.OpenItemAge{
+			SystemTrxID:    tx.TrxID,
+			Date:           tx.TransactionTime.Format(time.DateOnly),
+			FirstSeen:      firstSeen,
+			AgeDays:        dayOffset(tx.TransactionTime, end),
+			CarriedForward: isCarried,
+		})
+	}
+	for _, tx := range unmatchedBank {
+		tx := tx
+		firstSeen, isCarried := carried.bank[bankKeyOf(tx)]
+		if !isCarried {
+			firstSeen = asOf
+		}
+		stored.Items = append(stored.Items, domain.OpenItem{Bank: &tx, References: tx.ReferenceFields, FirstSeen: firstSeen})
+		openItems.Open = append(openItems.Open, domain.OpenItemAge{
+			BankUniqueIdentifier: tx.UniqueIdentifier,
+			BankSource:           tx.BankSource,
+			Date:                 tx.Date.Format(time.DateOnly),
+			FirstSeen:            firstSeen,
+			AgeDays:              dayOffset(tx.Date, end),
+			CarriedForward:       isCarried,
+		})
+	}
+
+	stillOpen := 0
+	for _, age := range openItems.Open {
+		if age.CarriedForward {
+			stillOpen++
+		}
+	}
+	openItems.Cleared = openItems.CarriedIn - stillOpen
+
+	if err := uc.openItems.Save(ctx, stored); err != nil {
+		return fmt.Errorf("could not save open items: %w", err)
+	}
+	report.OpenItems = openItems
+	return nil
+}
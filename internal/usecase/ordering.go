@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"sort"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// Matching and reporting never depend on map iteration order or on the order of the input
+// files: transactions are sorted by date, amount and ID before matching, and every report
+// section is sorted the same way, so identical input always yields an identical report.
+
+func sortSystemTransactions(txs []domain.SystemTransaction) {
+	sort.SliceStable(txs, func(i, j int) bool { return systemLess(txs[i], txs[j]) })
+}
+
+func sortBankTransactions(txs []domain.BankTransaction) {
+	sort.SliceStable(txs, func(i, j int) bool { return bankLess(txs[i], txs[j]) })
+}
+
+func systemLess(a, b domain.SystemTransaction) bool {
+	if !a.TransactionTime.Equal(b.TransactionTime) {
+		return a.TransactionTime.Before(b.TransactionTime)
+	}
+	if c := a.Amount.Cmp(b.Amount); c != 0 {
+		return c < 0
+	}
+	return a.TrxID < b.TrxID
+}
+
+func bankLess(a, b domain.BankTransaction) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.Before(b.Date)
+	}
+	if c := a.Amount.Cmp(b.Amount); c != 0 {
+		return c < 0
+	}
+	if a.BankSource != b.BankSource {
+		return a.BankSource < b.BankSource
+	}
+	return a.UniqueIdentifier < b.UniqueIdentifier
+}
+
+// sortReport orders the report sections by the date, amount and ID of their system side.
+func sortReport(report *domain.ReconciliationReport) {
+	details := report.DiscrepantTransactions.Details
+	sort.SliceStable(details, func(i, j int) bool {
+		return systemLess(details[i].SystemTransaction, details[j].SystemTransaction)
+	})
+
+	groups := report.GroupedMatches.Details
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if len(a.SystemTransactions) == 0 || len(b.SystemTransactions) == 0 {
+			return len(a.SystemTransactions) < len(b.SystemTransactions)
+		}
+		return systemLess(a.SystemTransactions[0], b.SystemTransactions[0])
+	})
+
+	sortPairs(report.LaggedMatches)
+	sortPairs(report.AmbiguousMatches)
+	sortPairs(report.ManualMatches)
+
+	if ignored := report.IgnoredTransactions; ignored != nil {
+		sortSystemTransactions(ignored.SystemTransactions)
+		sortBankTransactions(ignored.BankTransactions)
+	}
+
+	if report.IngestionErrors != nil {
+		rejected := report.IngestionErrors.Details
+		sort.SliceStable(rejected, func(i, j int) bool {
+			if rejected[i].File != rejected[j].File {
+				return rejected[i].File < rejected[j].File
+			}
+			return rejected[i].Line < rejected[j].Line
+		})
+	}
+
+	if report.MatchedTransactions != nil {
+		matched := report.MatchedTransactions.Details
+		sort.SliceStable(matched, func(i, j int) bool {
+			return pairLess(matched[i].MatchedPair, matched[j].MatchedPair)
+		})
+	}
+}
+
+func sortPairs(pairs []domain.MatchedPair) {
+	sort.SliceStable(pairs, func(i, j int) bool { return pairLess(pairs[i], pairs[j]) })
+}
+
+func pairLess(a, b domain.MatchedPair) bool {
+	if a.SystemDate != b.SystemDate {
+		return a.SystemDate < b.SystemDate
+	}
+	if a.SystemTrxID != b.SystemTrxID {
+		return a.SystemTrxID < b.SystemTrxID
+	}
+	if a.BankSource != b.BankSource {
+		return a.BankSource < b.BankSource
+	}
+	return a.BankUniqueIdentifier < b.BankUniqueIdentifier
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// manualConfidence is the confidence of a match decided by an override.
+const manualConfidence = 1.0
+
+// overrideMatcher applies manual overrides ahead of the automatic passes. Overrides that
+// cannot be applied are reported under override_errors rather than failing the run.
+type overrideMatcher struct {
+	overrides []domain.Override
+}
+
+func (overrideMatcher) Name() string {
+	return "manual"
+}
+
+func (m overrideMatcher) Match(ctx context.Context, state *MatchState) error {
+	for _, o := range m.overrides {
+		if err := m.apply(state, o); err != nil {
+			state.report.OverrideErrors = append(state.report.OverrideErrors, domain.OverrideError{
+				Override: o,
+				Error:    err.Error(),
+			})
+		}
+	}
+	return nil
+}
+
+func (m overrideMatcher) apply(state *MatchState, o domain.Override) error {
+	var sysTx *domain.SystemTransaction
+	var bankTx *domain.BankTransaction
+	if o.SystemTrxID != "" {
+		tx, err := findSystemTransaction(state, o.SystemTrxID)
+		if err != nil {
+			return err
+		}
+		sysTx = &tx
+	}
+	if o.BankUniqueIdentifier != "" {
+		tx, err := findBankTransaction(state, o.BankUniqueIdentifier, o.BankSource)
+		if err != nil {
+			return err
+		}
+		bankTx = &tx
+	}
+
+	switch o.Action {
+	case domain.OverrideMatch:
+		if sysTx == nil || bankTx == nil {
+			return fmt.Errorf("a match override needs both a system and a bank transaction")
+		}
+		state.match(*sysTx, *bankTx, Evidence{Key: o.Note, Confidence: manualConfidence}, matchManual)
+	case domain.OverrideIgnore:
+		if sysTx == nil && bankTx == nil {
+			return fmt.Errorf("an ignore override needs a system or a bank transaction")
+		}
+		if sysTx != nil {
+			state.ignoreSystem(*sysTx)
+		}
+		if bankTx != nil {
+			state.ignoreBank(*bankTx)
+		}
+	default:
+		return fmt.Errorf("unknown override action %q", o.Action)
+	}
+	return nil
+}
+
+// findSystemTransaction returns the unmatched system transaction with the given ID.
+func findSystemTransaction(state *MatchState, trxID string) (domain.SystemTransaction, error) {
+	for _, tx := range state.systemTxs {
+		if tx.TrxID != trxID {
+			continue
+		}
+		if state.IsSystemMatched(tx) {
+			return domain.SystemTransaction{}, fmt.Errorf("system transaction %s is already overridden", trxID)
+		}
+		return tx, nil
+	}
+	return domain.SystemTransaction{}, fmt.Errorf("system transaction %s not found in the reconciliation timeframe", trxID)
+}
+
+// findBankTransaction returns the unmatched bank transaction with the given identifier,
+// from the given statement file when bankSource is set.
+func findBankTransaction(state *MatchState, id, bankSource string) (domain.BankTransaction, error) {
+	var found []domain.BankTransaction
+	for _, tx := range state.bankTxs {
+		if tx.UniqueIdentifier == id && (bankSource == "" || tx.BankSource == bankSource) {
+			found = append(found, tx)
+		}
+	}
+	switch {
+	case len(found) == 0:
+		return domain.BankTransaction{}, fmt.Errorf("bank transaction %s not found in the reconciliation timeframe", id)
+	case len(found) > 1:
+		return domain.BankTransaction{}, fmt.Errorf("bank transaction %s appears on several statements, set bank_source", id)
+	case state.IsBankMatched(found[0]):
+		return domain.BankTransaction{}, fmt.Errorf("bank transaction %s is already overridden", id)
+	}
+	return found[0], nil
+}
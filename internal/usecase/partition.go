@@ -0,0 +1,530 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// reconcileByDate reconciles the period one system transaction date at a time. A date's
+// transactions go through the matching passes against the bank transactions within its
+// settlement window, each pass running only once the earlier passes have run on every
+// other date that could claim the same bank transactions. So only the dates within a few
+// settlement windows are held at once, and the matches are those found when the whole
+// period is held. Bank transactions that no date left can reach are settled as unmatched.
+func (uc *ReconciliationUseCase) reconcileByDate(ctx context.Context, systemSource domain.Source, bankSources []domain.Source, start, end time.Time) (*domain.ReconciliationReport, error) {
+	systemStream, err := uc.streamer.StreamSystemTransactions(ctx, systemSource, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not get system transactions: %w", err)
+	}
+	defer systemStream.Close()
+	bankStream, err := uc.streamer.StreamBankTransactions(ctx, bankSources, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not get bank transactions: %w", err)
+	}
+	defer bankStream.Close()
+
+	report := uc.newReport(start, end)
+	p := &partition{
+		uc:     uc,
+		system: newDayReader("system", systemStream, func(tx domain.SystemTransaction) time.Time { return tx.TransactionTime }),
+		bank:   newDayReader("bank", bankStream, func(tx domain.BankTransaction) time.Time { return tx.Date }),
+		state: &MatchState{
+			systemAmounts:     make(map[string]convertedAmount),
+			bankAmounts:       make(map[bankKey]convertedAmount),
+			matchedSystem:     make(map[string]bool),
+			matchedBank:       make(map[bankKey]bool),
+			reportingCurrency: uc.reportingCurrency,
+			settlement:        uc.settlement.startingOn(start),
+			report:            report,
+			explain:           uc.explain,
+		},
+		span:      uc.settlement.span(),
+		pending:   uc.overrides,
+		movements: newPeriodMovements(),
+		carried:   carriedItems{system: make(map[string]string), bank: make(map[bankKey]string)},
+	}
+	if len(uc.overrides) > 0 {
+		p.passes = append(p.passes, pendingOverrides{p})
+	}
+	for _, matcher := range uc.matchers {
+		if phased, ok := matcher.(phasedMatcher); ok {
+			p.passes = append(p.passes, phased.phases()...)
+		} else {
+			p.passes = append(p.passes, matcher)
+		}
+	}
+
+	// Bring in the items left open by earlier periods, each on its own date or, if earlier,
+	// the first date of the period
+	if uc.openItems != nil {
+		stored, err := uc.openItems.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not load open items: %w", err)
+		}
+		for _, item := range stored.Items {
+			switch {
+			case item.System != nil:
+				p.system.carry(p.state.settlement.day(item.System.TransactionTime), *item.System)
+				p.carried.system[item.System.TrxID] = item.FirstSeen
+			case item.Bank != nil:
+				p.bank.carry(p.state.settlement.day(item.Bank.Date), item.BankTransaction())
+				p.carried.bank[bankKeyOf(*item.Bank)] = item.FirstSeen
+			}
+		}
+	}
+
+	if err := p.run(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, o := range p.pending {
+		report.OverrideErrors = append(report.OverrideErrors, domain.OverrideError{
+			Override: o,
+			Error:    "transactions not found within one settlement window in the reconciliation timeframe",
+		})
+	}
+	report.ReconciliationSummary.TotalSystemTransactionsProcessed = p.systemCount
+	report.ReconciliationSummary.TotalBankTransactionsProcessed = p.bankCount
+	if err := uc.finish(ctx, report, bankSources, p.unmatchedSystem, p.unmatchedBank, p.movements, p.carried, end); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// phasedMatcher is a matcher whose work falls into phases, each of which must run on every
+// date before the next one does.
+type phasedMatcher interface {
+	phases() []Matcher
+}
+
+// partition is the progress of a reconciliation by date.
+type partition struct {
+	uc     *ReconciliationUseCase
+	system *dayReader[domain.SystemTransaction]
+	bank   *dayReader[domain.BankTransaction]
+	state  *MatchState
+	passes []Matcher
+	span   int // calendar days the settlement window reaches on either side of a date
+
+	// days holds the system transactions of the dates still going through the passes
+	days []*systemDay
+	// window holds the unmatched bank transactions within reach of those dates, in date,
+	// amount and ID order
+	window []domain.BankTransaction
+
+	pending         []domain.Override // overrides not applied yet
+	movements       periodMovements
+	carried         carriedItems
+	systemCount     int
+	bankCount       int
+	unmatchedSystem []domain.SystemTransaction
+	unmatchedBank   []domain.BankTransaction
+}
+
+// systemDay is the system transactions of one date and how many passes they went through.
+type systemDay struct {
+	day    time.Time
+	txs    []domain.SystemTransaction
+	passes int
+}
+
+// due returns the step at which the date's next pass may run. Two dates further apart than
+// twice the span never compete for a bank transaction, so each pass waits until the one
+// before has run on the dates up to that far ahead.
+func (p *partition) due(d *systemDay) time.Time {
+	return d.day.AddDate(0, 0, 2*p.span*d.passes)
+}
+
+func (p *partition) run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		step, ok, err := p.nextStep()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		if err := p.loadSystem(ctx, step); err != nil {
+			return err
+		}
+		oldest := step
+		if len(p.days) > 0 {
+			oldest = p.days[0].day
+		}
+		if err := p.loadBank(ctx, oldest.AddDate(0, 0, -p.span), step.AddDate(0, 0, p.span)); err != nil {
+			return err
+		}
+		for pass := range p.passes {
+			for _, d := range p.days {
+				if d.passes == pass && !p.due(d).After(step) {
+					if err := p.runPass(ctx, d); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		p.settle()
+	}
+
+	// The remaining bank transactions have no system transactions left to match
+	for {
+		day, ok, err := p.bank.nextDay()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if err := p.loadBank(ctx, day.AddDate(0, 0, 1), day); err != nil {
+			return err
+		}
+	}
+	return p.closeBank(ctx, len(p.window))
+}
+
+// nextStep returns the earliest of the next system transaction date and the dates at which
+// the dates held are due their next pass.
+func (p *partition) nextStep() (time.Time, bool, error) {
+	step, ok, err := p.system.nextDay()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for _, d := range p.days {
+		if due := p.due(d); !ok || due.Before(step) {
+			step, ok = due, true
+		}
+	}
+	return step, ok, nil
+}
+
+// loadSystem adds the system transactions dated day, if any, to the dates held.
+func (p *partition) loadSystem(ctx context.Context, day time.Time) error {
+	next, ok, err := p.system.nextDay()
+	if err != nil || !ok || !next.Equal(day) {
+		return err
+	}
+	own, carried, err := p.system.take(day)
+	if err != nil {
+		return err
+	}
+	p.systemCount += len(own)
+	present := make(map[string]bool, len(own))
+	for _, tx := range own {
+		present[tx.TrxID] = true
+	}
+	txs := own
+	for _, tx := range carried {
+		// Items that are also in the period's own files are not carried
+		if present[tx.TrxID] {
+			delete(p.carried.system, tx.TrxID)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+
+	for _, tx := range txs {
+		converted, err := p.uc.toReporting(ctx, tx.Amount, tx.Currency, tx.TransactionTime)
+		if err != nil {
+			return fmt.Errorf("could not convert amounts to %s: system transaction %s: %w", p.uc.reportingCurrency, tx.TrxID, err)
+		}
+		p.state.systemAmounts[tx.TrxID] = converted
+		if present[tx.TrxID] {
+			p.movements.addSystem(tx, converted.amount)
+		}
+	}
+	sortSystemTransactions(txs)
+	p.days = append(p.days, &systemDay{day: day, txs: txs})
+	return nil
+}
+
+// loadBank adds the bank transactions dated up to through to the window, settling those
+// dated before from as it goes.
+func (p *partition) loadBank(ctx context.Context, from, through time.Time) error {
+	for {
+		if err := p.closeBankBefore(ctx, from); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		day, ok, err := p.bank.nextDay()
+		if err != nil {
+			return err
+		}
+		if !ok || day.After(through) {
+			return nil
+		}
+
+		own, carried, err := p.bank.take(day)
+		if err != nil {
+			return err
+		}
+		p.bankCount += len(own)
+		present := make(map[bankKey]bool, len(own))
+		for _, tx := range own {
+			present[bankKeyOf(tx)] = true
+			p.movements.addBank(tx)
+		}
+		txs := own
+		for _, tx := range carried {
+			if present[bankKeyOf(tx)] {
+				delete(p.carried.bank, bankKeyOf(tx))
+				continue
+			}
+			txs = append(txs, tx)
+		}
+
+		for _, tx := range txs {
+			converted, err := p.uc.toReporting(ctx, tx.NormalizedAmount, tx.Currency, tx.Date)
+			if err != nil {
+				return fmt.Errorf("could not convert amounts to %s: bank transaction %s: %w", p.uc.reportingCurrency, tx.UniqueIdentifier, err)
+			}
+			p.state.bankAmounts[bankKeyOf(tx)] = converted
+		}
+		sortBankTransactions(txs)
+		p.window = append(p.window, txs...)
+	}
+}
+
+// runPass runs the date's next pass against the bank transactions within its settlement window.
+func (p *partition) runPass(ctx context.Context, d *systemDay) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	matcher := p.passes[d.passes]
+	state := p.state
+	state.systemTxs, state.bankTxs = d.txs, p.bankBetween(d.day.AddDate(0, 0, -p.span), d.day.AddDate(0, 0, p.span))
+	state.pass = matcher.Name()
+	err := matcher.Match(ctx, state)
+	state.systemTxs, state.bankTxs = nil, nil
+	if err != nil {
+		return fmt.Errorf("%s matching failed: %w", matcher.Name(), err)
+	}
+	d.passes++
+	return nil
+}
+
+// bankBetween returns the bank transactions in the window dated from one day through another.
+func (p *partition) bankBetween(from, through time.Time) []domain.BankTransaction {
+	settlement := p.state.settlement
+	i := sort.Search(len(p.window), func(i int) bool { return !settlement.day(p.window[i].Date).Before(from) })
+	j := sort.Search(len(p.window), func(i int) bool { return settlement.day(p.window[i].Date).After(through) })
+	return p.window[i:j]
+}
+
+// settle lets go of the dates that went through every pass, leaving their unmatched system
+// transactions unmatched, and drops matched bank transactions from the window.
+func (p *partition) settle() {
+	state := p.state
+	days := p.days[:0]
+	for _, d := range p.days {
+		if d.passes < len(p.passes) {
+			days = append(days, d)
+			continue
+		}
+		for _, tx := range d.txs {
+			if !state.matchedSystem[tx.TrxID] {
+				p.unmatchedSystem = append(p.unmatchedSystem, tx)
+			}
+			delete(state.systemAmounts, tx.TrxID)
+			delete(state.matchedSystem, tx.TrxID)
+		}
+	}
+	clear(p.days[len(days):])
+	p.days = days
+
+	window := p.window[:0]
+	for _, tx := range p.window {
+		if state.matchedBank[bankKeyOf(tx)] {
+			delete(state.bankAmounts, bankKeyOf(tx))
+			delete(state.matchedBank, bankKeyOf(tx))
+			continue
+		}
+		window = append(window, tx)
+	}
+	clear(p.window[len(window):])
+	p.window = window
+}
+
+// closeBankBefore settles the bank transactions in the window dated before day.
+func (p *partition) closeBankBefore(ctx context.Context, day time.Time) error {
+	n := sort.Search(len(p.window), func(i int) bool {
+		return !p.state.settlement.day(p.window[i].Date).Before(day)
+	})
+	return p.closeBank(ctx, n)
+}
+
+// closeBank settles the first n bank transactions in the window, which no system
+// transaction left can match: overrides ignoring them are applied and the rest are left
+// unmatched.
+func (p *partition) closeBank(ctx context.Context, n int) error {
+	if n == 0 {
+		return nil
+	}
+	closing := p.window[:n]
+	state := p.state
+	if len(p.pending) > 0 {
+		matcher := pendingOverrides{p}
+		state.systemTxs, state.bankTxs = nil, closing
+		state.pass = matcher.Name()
+		err := matcher.Match(ctx, state)
+		state.bankTxs = nil
+		if err != nil {
+			return fmt.Errorf("%s matching failed: %w", matcher.Name(), err)
+		}
+	}
+
+	for _, tx := range closing {
+		if !state.matchedBank[bankKeyOf(tx)] {
+			p.unmatchedBank = append(p.unmatchedBank, tx)
+		}
+		delete(state.bankAmounts, bankKeyOf(tx))
+		delete(state.matchedBank, bankKeyOf(tx))
+	}
+	kept := copy(p.window, p.window[n:])
+	clear(p.window[kept:])
+	p.window = p.window[:kept]
+	return nil
+}
+
+// pendingOverrides applies the overrides whose transactions are all in the state, leaving
+// the others pending for a later date.
+type pendingOverrides struct {
+	p *partition
+}
+
+func (pendingOverrides) Name() string {
+	return overrideMatcher{}.Name()
+}
+
+func (m pendingOverrides) Match(ctx context.Context, state *MatchState) error {
+	var ready, pending []domain.Override
+	for _, o := range m.p.pending {
+		if overrideInState(state, o) {
+			ready = append(ready, o)
+		} else {
+			pending = append(pending, o)
+		}
+	}
+	m.p.pending = pending
+	return overrideMatcher{overrides: ready}.Match(ctx, state)
+}
+
+func overrideInState(state *MatchState, o domain.Override) bool {
+	if o.SystemTrxID != "" {
+		found := false
+		for _, tx := range state.systemTxs {
+			if tx.TrxID == o.SystemTrxID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if o.BankUniqueIdentifier != "" {
+		found := false
+		for _, tx := range state.bankTxs {
+			if tx.UniqueIdentifier == o.BankUniqueIdentifier && (o.BankSource == "" || tx.BankSource == o.BankSource) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// dayReader reads a stream ordered by date one calendar day at a time, along with the open
+// items carried in for each day.
+type dayReader[T any] struct {
+	kind    string // "system" or "bank", for errors
+	stream  domain.Stream[T]
+	date    func(T) time.Time
+	next    T
+	hasNext bool
+	done    bool
+	carried map[time.Time][]T
+}
+
+func newDayReader[T any](kind string, stream domain.Stream[T], date func(T) time.Time) *dayReader[T] {
+	return &dayReader[T]{kind: kind, stream: stream, date: date, carried: make(map[time.Time][]T)}
+}
+
+// carry adds an open item carried in from an earlier period, to be taken on day.
+func (r *dayReader[T]) carry(day time.Time, tx T) {
+	r.carried[day] = append(r.carried[day], tx)
+}
+
+// fill reads the next value of the stream unless one is waiting.
+func (r *dayReader[T]) fill() error {
+	if r.hasNext || r.done {
+		return nil
+	}
+	next, err := r.stream.Next()
+	if errors.Is(err, io.EOF) {
+		r.done = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get %s transactions: %w", r.kind, err)
+	}
+	r.next, r.hasNext = next, true
+	return nil
+}
+
+// nextDay returns the earliest day with transactions not taken yet.
+func (r *dayReader[T]) nextDay() (time.Time, bool, error) {
+	if err := r.fill(); err != nil {
+		return time.Time{}, false, err
+	}
+	var day time.Time
+	found := false
+	if r.hasNext {
+		day, found = dayOf(r.date(r.next)), true
+	}
+	for d := range r.carried {
+		if !found || d.Before(day) {
+			day, found = d, true
+		}
+	}
+	return day, found, nil
+}
+
+// take returns the transactions of day read from the stream and the items carried in on day.
+func (r *dayReader[T]) take(day time.Time) ([]T, []T, error) {
+	var own []T
+	for {
+		if err := r.fill(); err != nil {
+			return nil, nil, err
+		}
+		if !r.hasNext {
+			break
+		}
+		next := dayOf(r.date(r.next))
+		if next.After(day) {
+			break
+		}
+		if next.Before(day) {
+			return nil, nil, fmt.Errorf("could not get %s transactions: %s comes after %s, the stream is not in date order", r.kind, next.Format(time.DateOnly), day.Format(time.DateOnly))
+		}
+		own = append(own, r.next)
+		r.hasNext = false
+	}
+	carried := r.carried[day]
+	delete(r.carried, day)
+	return own, carried, nil
+}
@@ -3,8 +3,6 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"math"
-	"strings"
 	"time"
 
 	"mini-reconciliation/internal/domain"
@@ -12,23 +10,181 @@ import (
 
 // ReconciliationUseCase orchestrates the reconciliation process.
 type ReconciliationUseCase struct {
-	repo TransactionRepository
+	repo              TransactionRepository
+	fxRates           FXRateProvider
+	reportingCurrency domain.Currency
+	settlement        settlementWindow
+	matchers          []Matcher
+	overrides         []domain.Override
+	openItems         OpenItemStore
+	checkBalances     bool
+	statementBalances []domain.StatementBalance
+	ledgerOpening     *domain.Decimal
+	explain           bool
+	ingestionErrors   IngestionErrorSource
+	streamer          TransactionStreamer
+}
+
+// Option configures a ReconciliationUseCase.
+type Option func(*ReconciliationUseCase)
+
+// WithReportingCurrency sets the currency amounts are compared and reported in.
+// It defaults to domain.DefaultCurrency.
+func WithReportingCurrency(currency domain.Currency) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.reportingCurrency = domain.ParseCurrency(string(currency))
+	}
+}
+
+// WithFXRates sets the exchange rate source used to convert foreign-currency transactions
+// into the reporting currency.
+func WithFXRates(rates FXRateProvider) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.fxRates = rates
+	}
+}
+
+// WithSettlementLag lets exact and group matching pair a system transaction with a bank
+// booking up to days apart, preferring the closest date. With businessDays set, only
+// Monday to Friday count towards the lag.
+func WithSettlementLag(days int, businessDays bool) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.settlement = settlementWindow{days: days, businessDays: businessDays}
+	}
+}
+
+// WithMatchers sets the matching pipeline. Matchers run in the order given, each seeing only
+// the transactions left unmatched by those before it. It replaces DefaultMatchers.
+func WithMatchers(matchers ...Matcher) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.matchers = matchers
+	}
+}
+
+// WithOverrides applies manual overrides before the matching pipeline runs. Overridden
+// pairs are reported as manual matches, ignored transactions are left out of the unmatched
+// lists, and overrides naming unknown or already used transactions are reported as errors.
+func WithOverrides(overrides []domain.Override) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.overrides = overrides
+	}
+}
+
+// WithOpenItems carries unmatched transactions across periods: the items the store holds
+// from earlier periods are matched along with the period's transactions, as if dated on
+// its first date, and whatever is left unmatched is saved back to the store and reported
+// with its age.
+func WithOpenItems(store OpenItemStore) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.openItems = store
+	}
+}
+
+// WithBalanceReconciliation checks that each bank statement's opening balance plus its
+// movements in the period equals its closing balance, reported under the summary's balances.
+// Balances are read from the statements; balances given here take precedence.
+func WithBalanceReconciliation(balances ...domain.StatementBalance) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.checkBalances = true
+		uc.statementBalances = balances
+	}
+}
+
+// WithLedgerOpeningBalance sets the system ledger balance at the start of the period, so
+// that the expected closing ledger balance is compared with the total of the bank closing
+// balances. It implies WithBalanceReconciliation.
+func WithLedgerOpeningBalance(opening domain.Decimal) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.checkBalances = true
+		uc.ledgerOpening = &opening
+	}
+}
+
+// WithIngestionErrors lists the input rows skipped by a lenient repository in the
+// ingestion_errors section of the report.
+func WithIngestionErrors(source IngestionErrorSource) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.ingestionErrors = source
+	}
+}
+
+// WithDatePartitioning reconciles one date at a time to bound memory use on large inputs.
+// Transactions are streamed from streamer, already filtered to the timeframe, and only the
+// dates within a few settlement windows are held at once, however long the period. Matches
+// are the same as when the whole period is held, except that they are only found within
+// the settlement window, also by the reference matcher and manual overrides, and that
+// aggregate matching only sums system transactions of the same date.
+func WithDatePartitioning(streamer TransactionStreamer) Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.streamer = streamer
+	}
+}
+
+// WithMatchExplanations lists every matched pair in the report's matched_transactions
+// section, with the pass that matched it, the key it was matched on and a confidence score.
+func WithMatchExplanations() Option {
+	return func(uc *ReconciliationUseCase) {
+		uc.explain = true
+	}
+}
+
+// DefaultMatchers returns the pipeline used unless WithMatchers is given:
+// reference, exact and group matching.
+func DefaultMatchers() []Matcher {
+	return []Matcher{ReferenceMatcher(), ExactMatcher(), GroupMatcher()}
 }
 
 // NewReconciliationUseCase creates a new instance of the usecase.
-func NewReconciliationUseCase(repo TransactionRepository) *ReconciliationUseCase {
-	return &ReconciliationUseCase{repo: repo}
+func NewReconciliationUseCase(repo TransactionRepository, opts ...Option) *ReconciliationUseCase {
+	uc := &ReconciliationUseCase{
+		repo:              repo,
+		reportingCurrency: domain.DefaultCurrency,
+		matchers:          DefaultMatchers(),
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// RunParameters describes the settings a reconciliation from start to end runs with.
+func (uc *ReconciliationUseCase) RunParameters(start, end time.Time) domain.RunParameters {
+	params := domain.RunParameters{
+		Start:             start.Format(time.DateOnly),
+		End:               end.Format(time.DateOnly),
+		ReportingCurrency: uc.reportingCurrency,
+		SettlementLagDays: uc.settlement.days,
+		BusinessDays:      uc.settlement.businessDays,
+		Passes:            make([]string, 0, len(uc.matchers)),
+	}
+	for _, matcher := range uc.matchers {
+		params.Passes = append(params.Passes, matcher.Name())
+		if described, ok := matcher.(describedMatcher); ok {
+			described.describe(&params)
+		}
+	}
+	return params
+}
+
+// describedMatcher is a matcher with settings of its own to record on a run.
+type describedMatcher interface {
+	describe(params *domain.RunParameters)
 }
 
-// Reconcile performs the main reconciliation logic.
-func (uc *ReconciliationUseCase) Reconcile(ctx context.Context, systemPath string, bankPaths []string, start, end time.Time) (*domain.ReconciliationReport, error) {
+// Reconcile performs the main reconciliation logic on the system transactions source and
+// the bank statement sources.
+func (uc *ReconciliationUseCase) Reconcile(ctx context.Context, systemSource domain.Source, bankSources []domain.Source, start, end time.Time) (*domain.ReconciliationReport, error) {
+	if uc.streamer != nil {
+		return uc.reconcileByDate(ctx, systemSource, bankSources, start, end)
+	}
+
 	// Step 1: Data Ingestion
-	systemTransactions, err := uc.repo.GetSystemTransactions(ctx, systemPath)
+	systemTransactions, err := uc.repo.GetSystemTransactions(ctx, systemSource)
 	if err != nil {
 		return nil, fmt.Errorf("could not get system transactions: %w", err)
 	}
 
-	bankTransactions, err := uc.repo.GetBankTransactions(ctx, bankPaths)
+	bankTransactions, err := uc.repo.GetBankTransactions(ctx, bankSources)
 	if err != nil {
 		return nil, fmt.Errorf("could not get bank transactions: %w", err)
 	}
@@ -36,105 +192,137 @@ func (uc *ReconciliationUseCase) Reconcile(ctx context.Context, systemPath strin
 	// Step 2: Timeframe Filtering
 	filteredSystemTx := filterSystemTransactionsByDate(systemTransactions, start, end)
 	filteredBankTx := filterBankTransactionsByDate(bankTransactions, start, end)
+	periodSystemCount, periodBankCount := len(filteredSystemTx), len(filteredBankTx)
+
+	// Bring in the items left open by earlier periods
+	var carried carriedItems
+	if uc.openItems != nil {
+		filteredSystemTx, filteredBankTx, carried, err = uc.carryIn(ctx, filteredSystemTx, filteredBankTx)
+		if err != nil {
+			return nil, fmt.Errorf("could not load open items: %w", err)
+		}
+	}
+	sortSystemTransactions(filteredSystemTx)
+	sortBankTransactions(filteredBankTx)
+
+	// Express every amount in the reporting currency so that pairs can be compared
+	systemAmounts, bankAmounts, err := uc.convertAmounts(ctx, filteredSystemTx, filteredBankTx)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert amounts to %s: %w", uc.reportingCurrency, err)
+	}
+
+	report := uc.newReport(start, end)
+	report.ReconciliationSummary.TotalSystemTransactionsProcessed = periodSystemCount
+	report.ReconciliationSummary.TotalBankTransactionsProcessed = periodBankCount
+
+	// Step 3: Multi-Pass Matching Strategy
+	state := &MatchState{
+		systemTxs:         filteredSystemTx,
+		bankTxs:           filteredBankTx,
+		systemAmounts:     systemAmounts,
+		bankAmounts:       bankAmounts,
+		matchedSystem:     make(map[string]bool),
+		matchedBank:       make(map[bankKey]bool),
+		reportingCurrency: uc.reportingCurrency,
+		settlement:        uc.settlement.startingOn(start),
+		report:            report,
+		explain:           uc.explain,
+	}
+	matchers := uc.matchers
+	if len(uc.overrides) > 0 {
+		matchers = append([]Matcher{overrideMatcher{overrides: uc.overrides}}, matchers...)
+	}
+	for _, matcher := range matchers {
+		// Give up between passes once the caller no longer wants the report
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		state.pass = matcher.Name()
+		if err := matcher.Match(ctx, state); err != nil {
+			return nil, fmt.Errorf("%s matching failed: %w", matcher.Name(), err)
+		}
+	}
+
+	// Step 4: Collate Unmatched Transactions
+	movements := newPeriodMovements()
+	for _, tx := range state.systemTxs {
+		if _, ok := carried.system[tx.TrxID]; !ok {
+			movements.addSystem(tx, state.SystemAmount(tx))
+		}
+	}
+	for _, tx := range state.bankTxs {
+		if _, ok := carried.bank[bankKeyOf(tx)]; !ok {
+			movements.addBank(tx)
+		}
+	}
+	if err := uc.finish(ctx, report, bankSources, state.UnmatchedSystem(), state.UnmatchedBank(), movements, carried, end); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
 
-	report := domain.ReconciliationReport{
+// newReport returns an empty report of the timeframe from start to end.
+func (uc *ReconciliationUseCase) newReport(start, end time.Time) *domain.ReconciliationReport {
+	report := &domain.ReconciliationReport{
 		ReconciliationSummary: domain.Summary{
-			TimeframeStart:                   start.Format(time.DateOnly),
-			TimeframeEnd:                     end.Format(time.DateOnly),
-			TotalSystemTransactionsProcessed: len(filteredSystemTx),
-			TotalBankTransactionsProcessed:   len(filteredBankTx),
+			TimeframeStart:    start.Format(time.DateOnly),
+			TimeframeEnd:      end.Format(time.DateOnly),
+			ReportingCurrency: uc.reportingCurrency,
 		},
 		DiscrepantTransactions: domain.DiscrepantTransactions{
-			Details: make([]domain.DiscrepancyDetail, 0),
+			Currency: uc.reportingCurrency,
+			Details:  make([]domain.DiscrepancyDetail, 0),
+		},
+		GroupedMatches: domain.GroupedMatches{
+			Details: make([]domain.GroupedMatch, 0),
 		},
 		UnmatchedTransactions: domain.UnmatchedTransactions{
 			BankMissingFromSystem: make(map[string][]domain.BankTransaction),
 		},
 	}
-
-	// Step 3: Multi-Pass Matching Strategy
-	matchedSystem := make(map[string]bool)
-	matchedBank := make(map[string]bool)
-
-	// Pass 1: Unique Identifier Matching (trxID in description)
-	for _, bankTx := range filteredBankTx {
-		for _, sysTx := range filteredSystemTx {
-			if matchedSystem[sysTx.TrxID] || matchedBank[bankTx.UniqueIdentifier] {
-				continue
-			}
-			if strings.Contains(bankTx.Description, "trxID:"+sysTx.TrxID) {
-				uc.processMatch(&report, sysTx, bankTx)
-				matchedSystem[sysTx.TrxID] = true
-				matchedBank[bankTx.UniqueIdentifier] = true
-			}
+	if uc.explain {
+		report.MatchedTransactions = &domain.MatchedTransactions{
+			Details: make([]domain.MatchExplanation, 0),
 		}
 	}
+	return report
+}
 
-	// Pass 2 & 3: Exact and Group Matching
-	// Create a map to group transactions by a composite key of date, type, and amount.
-	systemMap := make(map[string][]domain.SystemTransaction)
-	bankMap := make(map[string][]domain.BankTransaction)
-
-	for _, sysTx := range filteredSystemTx {
-		if !matchedSystem[sysTx.TrxID] {
-			key := buildGroupKey(sysTx.TransactionTime, sysTx.Type, sysTx.Amount)
-			systemMap[key] = append(systemMap[key], sysTx)
-		}
-	}
-	for _, bankTx := range filteredBankTx {
-		if !matchedBank[bankTx.UniqueIdentifier] {
-			key := buildGroupKey(bankTx.Date, bankTx.Type, bankTx.NormalizedAmount)
-			bankMap[key] = append(bankMap[key], bankTx)
+// finish completes the report once matching is done: it reconciles balances, lists the
+// transactions left unmatched and the rows skipped on ingestion, and carries the unmatched
+// transactions forward as open items.
+func (uc *ReconciliationUseCase) finish(ctx context.Context, report *domain.ReconciliationReport, bankSources []domain.Source, unmatchedSystem []domain.SystemTransaction, unmatchedBank []domain.BankTransaction, movements periodMovements, carried carriedItems, end time.Time) error {
+	if uc.checkBalances {
+		balances, err := uc.reconcileBalances(ctx, bankSources, movements, end)
+		if err != nil {
+			return fmt.Errorf("could not reconcile balances: %w", err)
 		}
+		report.ReconciliationSummary.Balances = balances
 	}
 
-	for key, sysTxs := range systemMap {
-		bankTxs, ok := bankMap[key]
-		if ok && len(sysTxs) == len(bankTxs) { // Pass 2 (len=1) and Pass 3 (len>1)
-			for i := 0; i < len(sysTxs); i++ {
-				uc.processMatch(&report, sysTxs[i], bankTxs[i])
-				matchedSystem[sysTxs[i].TrxID] = true
-				matchedBank[bankTxs[i].UniqueIdentifier] = true
-			}
-			// Remove matched items from maps
-			delete(systemMap, key)
-			delete(bankMap, key)
-		}
+	report.UnmatchedTransactions.SystemMissingFromBank = unmatchedSystem
+	for _, bankTx := range unmatchedBank {
+		report.UnmatchedTransactions.BankMissingFromSystem[bankTx.BankSource] = append(report.UnmatchedTransactions.BankMissingFromSystem[bankTx.BankSource], bankTx)
 	}
 
-	// Step 4: Collate Unmatched Transactions
-	for _, sysTxs := range systemMap {
-		report.UnmatchedTransactions.SystemMissingFromBank = append(report.UnmatchedTransactions.SystemMissingFromBank, sysTxs...)
-	}
-	for _, bankTxs := range bankMap {
-		for _, bankTx := range bankTxs {
-			report.UnmatchedTransactions.BankMissingFromSystem[bankTx.BankSource] = append(report.UnmatchedTransactions.BankMissingFromSystem[bankTx.BankSource], bankTx)
+	if uc.ingestionErrors != nil {
+		rejected := uc.ingestionErrors.IngestionErrors()
+		report.IngestionErrors = &domain.IngestionErrors{
+			Count:   len(rejected),
+			Details: append(make([]domain.IngestionError, 0, len(rejected)), rejected...),
 		}
 	}
 
 	// Calculate count AFTER populating unmatched transactions
 	report.UnmatchedTransactions.Count = len(report.UnmatchedTransactions.SystemMissingFromBank) + countBankMapItems(report.UnmatchedTransactions.BankMissingFromSystem)
+	sortReport(report)
 
-	return &report, nil
-}
-
-// processMatch handles a matched pair, checking for discrepancies.
-func (uc *ReconciliationUseCase) processMatch(report *domain.ReconciliationReport, sysTx domain.SystemTransaction, bankTx domain.BankTransaction) {
-	report.ReconciliationSummary.MatchedTransactions++
-	// Check for discrepancy (using a small epsilon for float comparison)
-	if math.Abs(sysTx.Amount-bankTx.NormalizedAmount) > 0.001 {
-		diff := math.Abs(sysTx.Amount - bankTx.NormalizedAmount)
-		report.DiscrepantTransactions.Count++
-		report.DiscrepantTransactions.TotalDiscrepancyValue += diff
-		report.DiscrepantTransactions.Details = append(report.DiscrepantTransactions.Details, domain.DiscrepancyDetail{
-			SystemTransaction: sysTx,
-			BankTransaction:   bankTx,
-		})
+	if uc.openItems != nil {
+		if err := uc.carryOut(ctx, report, unmatchedSystem, unmatchedBank, carried, end); err != nil {
+			return err
+		}
 	}
-}
-
-func buildGroupKey(t time.Time, txType domain.TransactionType, amount float64) string {
-	return string(fmt.Sprintf("%s-%s-%.2f", t.Format("2006-01-02"), txType, amount))
+	return nil
 }
 
 func filterSystemTransactionsByDate(transactions []domain.SystemTransaction, start, end time.Time) []domain.SystemTransaction {
@@ -2,10 +2,15 @@ package usecase_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand"
 	"mini-reconciliation/internal/domain"
 	"mini-reconciliation/internal/usecase"
 	mock_usecase "mini-reconciliation/internal/usecase/mocks"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -45,19 +50,19 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					TrxID:           "TRX001",
 					TransactionTime: baseTime.AddDate(0, 0, 1),
 					Type:            domain.TransactionTypeDebit,
-					Amount:          100.00,
+					Amount:          domain.MustParseDecimal("100.00"),
 				},
 				{
 					TrxID:           "TRX002",
 					TransactionTime: baseTime.AddDate(0, 0, 2),
 					Type:            domain.TransactionTypeCredit,
-					Amount:          250.50,
+					Amount:          domain.MustParseDecimal("250.50"),
 				},
 				{
 					TrxID:           "TRX003",
 					TransactionTime: baseTime.AddDate(0, 0, 3),
 					Type:            domain.TransactionTypeDebit,
-					Amount:          75.25,
+					Amount:          domain.MustParseDecimal("75.25"),
 				},
 			},
 			bankTxs: []domain.BankTransaction{
@@ -65,7 +70,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK001",
 					Date:             baseTime.AddDate(0, 0, 1),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 100.00,
+					NormalizedAmount: domain.MustParseDecimal("100.00"),
 					Description:      "Purchase trxID:TRX001",
 					BankSource:       "Bank1",
 				},
@@ -73,7 +78,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK002",
 					Date:             baseTime.AddDate(0, 0, 2),
 					Type:             domain.TransactionTypeCredit,
-					NormalizedAmount: 250.50,
+					NormalizedAmount: domain.MustParseDecimal("250.50"),
 					Description:      "Deposit",
 					BankSource:       "Bank1",
 				},
@@ -81,7 +86,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK003",
 					Date:             baseTime.AddDate(0, 0, 3),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 75.25,
+					NormalizedAmount: domain.MustParseDecimal("75.25"),
 					Description:      "Withdrawal",
 					BankSource:       "Bank2",
 				},
@@ -90,6 +95,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				ReconciliationSummary: domain.Summary{
 					TimeframeStart:                   start.Format(time.DateOnly),
 					TimeframeEnd:                     end.Format(time.DateOnly),
+					ReportingCurrency:                domain.DefaultCurrency,
 					TotalSystemTransactionsProcessed: 3,
 					TotalBankTransactionsProcessed:   3,
 					MatchedTransactions:              3,
@@ -113,13 +119,13 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					TrxID:           "TRX001",
 					TransactionTime: baseTime.AddDate(0, 0, 1),
 					Type:            domain.TransactionTypeDebit,
-					Amount:          100.00,
+					Amount:          domain.MustParseDecimal("100.00"),
 				},
 				{
 					TrxID:           "TRX002",
 					TransactionTime: baseTime.AddDate(0, 0, 2),
 					Type:            domain.TransactionTypeCredit,
-					Amount:          250.50,
+					Amount:          domain.MustParseDecimal("250.50"),
 				},
 			},
 			bankTxs: []domain.BankTransaction{
@@ -127,7 +133,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK001",
 					Date:             baseTime.AddDate(0, 0, 1),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 99.95, // Small discrepancy
+					NormalizedAmount: domain.MustParseDecimal("99.95"), // Small discrepancy
 					Description:      "Purchase trxID:TRX001",
 					BankSource:       "Bank1",
 				},
@@ -135,7 +141,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK002",
 					Date:             baseTime.AddDate(0, 0, 2),
 					Type:             domain.TransactionTypeCredit,
-					NormalizedAmount: 251.00, // Discrepancy
+					NormalizedAmount: domain.MustParseDecimal("251.00"), // Discrepancy
 					Description:      "Deposit trxID:TRX002",
 					BankSource:       "Bank1",
 				},
@@ -144,26 +150,27 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				ReconciliationSummary: domain.Summary{
 					TimeframeStart:                   start.Format(time.DateOnly),
 					TimeframeEnd:                     end.Format(time.DateOnly),
+					ReportingCurrency:                domain.DefaultCurrency,
 					TotalSystemTransactionsProcessed: 2,
 					TotalBankTransactionsProcessed:   2,
 					MatchedTransactions:              2,
 				},
 				DiscrepantTransactions: domain.DiscrepantTransactions{
 					Count:                 2,
-					TotalDiscrepancyValue: 0.55, // 0.05 + 0.50
+					TotalDiscrepancyValue: domain.MustParseDecimal("0.55"), // 0.05 + 0.50
 					Details: []domain.DiscrepancyDetail{
 						{
 							SystemTransaction: domain.SystemTransaction{
 								TrxID:           "TRX001",
 								TransactionTime: baseTime.AddDate(0, 0, 1),
 								Type:            domain.TransactionTypeDebit,
-								Amount:          100.00,
+								Amount:          domain.MustParseDecimal("100.00"),
 							},
 							BankTransaction: domain.BankTransaction{
 								UniqueIdentifier: "BANK001",
 								Date:             baseTime.AddDate(0, 0, 1),
 								Type:             domain.TransactionTypeDebit,
-								NormalizedAmount: 99.95,
+								NormalizedAmount: domain.MustParseDecimal("99.95"),
 								Description:      "Purchase trxID:TRX001",
 								BankSource:       "Bank1",
 							},
@@ -173,13 +180,13 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 								TrxID:           "TRX002",
 								TransactionTime: baseTime.AddDate(0, 0, 2),
 								Type:            domain.TransactionTypeCredit,
-								Amount:          250.50,
+								Amount:          domain.MustParseDecimal("250.50"),
 							},
 							BankTransaction: domain.BankTransaction{
 								UniqueIdentifier: "BANK002",
 								Date:             baseTime.AddDate(0, 0, 2),
 								Type:             domain.TransactionTypeCredit,
-								NormalizedAmount: 251.00,
+								NormalizedAmount: domain.MustParseDecimal("251.00"),
 								Description:      "Deposit trxID:TRX002",
 								BankSource:       "Bank1",
 							},
@@ -202,13 +209,13 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					TrxID:           "TRX001",
 					TransactionTime: baseTime.AddDate(0, 0, 1),
 					Type:            domain.TransactionTypeDebit,
-					Amount:          100.00,
+					Amount:          domain.MustParseDecimal("100.00"),
 				},
 				{
 					TrxID:           "TRX999", // No matching bank transaction
 					TransactionTime: baseTime.AddDate(0, 0, 5),
 					Type:            domain.TransactionTypeCredit,
-					Amount:          500.00,
+					Amount:          domain.MustParseDecimal("500.00"),
 				},
 			},
 			bankTxs: []domain.BankTransaction{
@@ -216,7 +223,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK001",
 					Date:             baseTime.AddDate(0, 0, 1),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 100.00,
+					NormalizedAmount: domain.MustParseDecimal("100.00"),
 					Description:      "Purchase trxID:TRX001",
 					BankSource:       "Bank1",
 				},
@@ -224,7 +231,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK999", // No matching system transaction
 					Date:             baseTime.AddDate(0, 0, 4),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 75.00,
+					NormalizedAmount: domain.MustParseDecimal("75.00"),
 					Description:      "ATM Withdrawal",
 					BankSource:       "Bank1",
 				},
@@ -233,6 +240,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				ReconciliationSummary: domain.Summary{
 					TimeframeStart:                   start.Format(time.DateOnly),
 					TimeframeEnd:                     end.Format(time.DateOnly),
+					ReportingCurrency:                domain.DefaultCurrency,
 					TotalSystemTransactionsProcessed: 2,
 					TotalBankTransactionsProcessed:   2,
 					MatchedTransactions:              1,
@@ -247,7 +255,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 							TrxID:           "TRX999",
 							TransactionTime: baseTime.AddDate(0, 0, 5),
 							Type:            domain.TransactionTypeCredit,
-							Amount:          500.00,
+							Amount:          domain.MustParseDecimal("500.00"),
 						},
 					},
 					BankMissingFromSystem: map[string][]domain.BankTransaction{
@@ -256,7 +264,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 								UniqueIdentifier: "BANK999",
 								Date:             baseTime.AddDate(0, 0, 4),
 								Type:             domain.TransactionTypeDebit,
-								NormalizedAmount: 75.00,
+								NormalizedAmount: domain.MustParseDecimal("75.00"),
 								Description:      "ATM Withdrawal",
 								BankSource:       "Bank1",
 							},
@@ -276,13 +284,13 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					TrxID:           "TRX001",
 					TransactionTime: baseTime.AddDate(0, 0, 1),
 					Type:            domain.TransactionTypeDebit,
-					Amount:          100.00,
+					Amount:          domain.MustParseDecimal("100.00"),
 				},
 				{
 					TrxID:           "TRX002",
 					TransactionTime: baseTime.AddDate(0, 0, 1),
 					Type:            domain.TransactionTypeDebit,
-					Amount:          100.00,
+					Amount:          domain.MustParseDecimal("100.00"),
 				},
 			},
 			bankTxs: []domain.BankTransaction{
@@ -290,7 +298,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK001",
 					Date:             baseTime.AddDate(0, 0, 1),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 100.00,
+					NormalizedAmount: domain.MustParseDecimal("100.00"),
 					Description:      "Purchase 1",
 					BankSource:       "Bank1",
 				},
@@ -298,7 +306,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK002",
 					Date:             baseTime.AddDate(0, 0, 1),
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 100.00,
+					NormalizedAmount: domain.MustParseDecimal("100.00"),
 					Description:      "Purchase 2",
 					BankSource:       "Bank1",
 				},
@@ -307,6 +315,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				ReconciliationSummary: domain.Summary{
 					TimeframeStart:                   start.Format(time.DateOnly),
 					TimeframeEnd:                     end.Format(time.DateOnly),
+					ReportingCurrency:                domain.DefaultCurrency,
 					TotalSystemTransactionsProcessed: 2,
 					TotalBankTransactionsProcessed:   2,
 					MatchedTransactions:              2,
@@ -330,19 +339,19 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					TrxID:           "TRX001",
 					TransactionTime: baseTime.AddDate(0, 0, 1), // Within range
 					Type:            domain.TransactionTypeDebit,
-					Amount:          100.00,
+					Amount:          domain.MustParseDecimal("100.00"),
 				},
 				{
 					TrxID:           "TRX002",
 					TransactionTime: baseTime.AddDate(0, 0, -1), // Before start
 					Type:            domain.TransactionTypeCredit,
-					Amount:          250.50,
+					Amount:          domain.MustParseDecimal("250.50"),
 				},
 				{
 					TrxID:           "TRX003",
 					TransactionTime: baseTime.AddDate(0, 0, 10), // After end
 					Type:            domain.TransactionTypeDebit,
-					Amount:          75.25,
+					Amount:          domain.MustParseDecimal("75.25"),
 				},
 			},
 			bankTxs: []domain.BankTransaction{
@@ -350,7 +359,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK001",
 					Date:             baseTime.AddDate(0, 0, 1), // Within range
 					Type:             domain.TransactionTypeDebit,
-					NormalizedAmount: 100.00,
+					NormalizedAmount: domain.MustParseDecimal("100.00"),
 					Description:      "Purchase trxID:TRX001",
 					BankSource:       "Bank1",
 				},
@@ -358,7 +367,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 					UniqueIdentifier: "BANK002",
 					Date:             baseTime.AddDate(0, 0, -1), // Before start
 					Type:             domain.TransactionTypeCredit,
-					NormalizedAmount: 250.50,
+					NormalizedAmount: domain.MustParseDecimal("250.50"),
 					Description:      "Deposit",
 					BankSource:       "Bank1",
 				},
@@ -367,6 +376,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				ReconciliationSummary: domain.Summary{
 					TimeframeStart:                   start.Format(time.DateOnly),
 					TimeframeEnd:                     end.Format(time.DateOnly),
+					ReportingCurrency:                domain.DefaultCurrency,
 					TotalSystemTransactionsProcessed: 1,
 					TotalBankTransactionsProcessed:   1,
 					MatchedTransactions:              1,
@@ -410,6 +420,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				ReconciliationSummary: domain.Summary{
 					TimeframeStart:                   start.Format(time.DateOnly),
 					TimeframeEnd:                     end.Format(time.DateOnly),
+					ReportingCurrency:                domain.DefaultCurrency,
 					TotalSystemTransactionsProcessed: 0,
 					TotalBankTransactionsProcessed:   0,
 					MatchedTransactions:              0,
@@ -431,26 +442,26 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 			// Setup mock expectations
 			if tt.systemRepoError != nil {
 				mTransactionRepo.EXPECT().
-					GetSystemTransactions(gomock.Any(), tt.systemPath).
+					GetSystemTransactions(gomock.Any(), namedSource(tt.systemPath)).
 					Return(nil, tt.systemRepoError)
 			} else {
 				mTransactionRepo.EXPECT().
-					GetSystemTransactions(gomock.Any(), tt.systemPath).
+					GetSystemTransactions(gomock.Any(), namedSource(tt.systemPath)).
 					Return(tt.systemTxs, nil)
 
 				if tt.bankRepoError != nil {
 					mTransactionRepo.EXPECT().
-						GetBankTransactions(gomock.Any(), tt.bankPaths).
+						GetBankTransactions(gomock.Any(), namedSources(tt.bankPaths...)).
 						Return(nil, tt.bankRepoError)
 				} else {
 					mTransactionRepo.EXPECT().
-						GetBankTransactions(gomock.Any(), tt.bankPaths).
+						GetBankTransactions(gomock.Any(), namedSources(tt.bankPaths...)).
 						Return(tt.bankTxs, nil)
 				}
 			}
 
 			uc := usecase.NewReconciliationUseCase(mTransactionRepo)
-			got, gotErr := uc.Reconcile(context.Background(), tt.systemPath, tt.bankPaths, tt.start, tt.end)
+			got, gotErr := uc.Reconcile(context.Background(), namedSource(tt.systemPath), namedSources(tt.bankPaths...), tt.start, tt.end)
 
 			if tt.wantErr {
 				assert.Error(t, gotErr)
@@ -462,7 +473,7 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 				// Compare the reports carefully
 				assert.Equal(t, tt.want.ReconciliationSummary, got.ReconciliationSummary)
 				assert.Equal(t, tt.want.DiscrepantTransactions.Count, got.DiscrepantTransactions.Count)
-				assert.InDelta(t, tt.want.DiscrepantTransactions.TotalDiscrepancyValue, got.DiscrepantTransactions.TotalDiscrepancyValue, 0.001)
+				assert.Equal(t, tt.want.DiscrepantTransactions.TotalDiscrepancyValue, got.DiscrepantTransactions.TotalDiscrepancyValue)
 				assert.Equal(t, len(tt.want.DiscrepantTransactions.Details), len(got.DiscrepantTransactions.Details))
 
 				assert.Equal(t, tt.want.UnmatchedTransactions.Count, got.UnmatchedTransactions.Count)
@@ -473,3 +484,1541 @@ func TestReconciliationUseCase_Reconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestReconciliationUseCase_Reconcile_MultiCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("1645050"), Currency: "IDR", Type: domain.TransactionTypeCredit, TransactionTime: day.Add(10 * time.Hour)},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("3290100"), Currency: "IDR", Type: domain.TransactionTypeCredit, TransactionTime: day.Add(11 * time.Hour)},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("10.00"), Currency: "USD", Type: domain.TransactionTypeDebit, TransactionTime: day.Add(12 * time.Hour)},
+	}
+	bankTxs := []domain.BankTransaction{
+		// Matched by amount once converted: 100 USD × 16450.50 = 1,645,050 IDR
+		{UniqueIdentifier: "USD_1", Amount: domain.MustParseDecimal("100"), NormalizedAmount: domain.MustParseDecimal("100"), Currency: "USD", Type: domain.TransactionTypeCredit, Date: day, Description: "Incoming", BankSource: "usd.csv"},
+		// Matched by reference; 199.99 USD converts to 3,289,935.50 IDR, 164.50 short
+		{UniqueIdentifier: "USD_2", Amount: domain.MustParseDecimal("199.99"), NormalizedAmount: domain.MustParseDecimal("199.99"), Currency: "USD", Type: domain.TransactionTypeCredit, Date: day, Description: "trxID:SYS002", BankSource: "usd.csv"},
+		// Same currency on both sides: compared without conversion
+		{UniqueIdentifier: "USD_3", Amount: domain.MustParseDecimal("-10"), NormalizedAmount: domain.MustParseDecimal("10"), Currency: "USD", Type: domain.TransactionTypeDebit, Date: day, Description: "trxID:SYS003", BankSource: "usd.csv"},
+	}
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), namedSource("system.csv")).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), namedSources("usd.csv")).Return(bankTxs, nil)
+
+	rate := domain.FXRate{From: "USD", To: "IDR", Date: day, Rate: domain.MustParseDecimal("16450.50")}
+	fx := mock_usecase.NewMockFXRateProvider(ctrl)
+	fx.EXPECT().Rate(gomock.Any(), domain.Currency("USD"), domain.Currency("IDR"), gomock.Any()).Return(rate, nil).AnyTimes()
+
+	uc := usecase.NewReconciliationUseCase(repo, usecase.WithReportingCurrency("IDR"), usecase.WithFXRates(fx))
+	got, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("usd.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, domain.Currency("IDR"), got.ReconciliationSummary.ReportingCurrency)
+	assert.Equal(t, 3, got.ReconciliationSummary.MatchedTransactions)
+	assert.Equal(t, 0, got.UnmatchedTransactions.Count)
+	assert.Equal(t, 1, got.DiscrepantTransactions.Count)
+	assert.Equal(t, domain.MustParseDecimal("164.50"), got.DiscrepantTransactions.TotalDiscrepancyValue)
+	if assert.Len(t, got.DiscrepantTransactions.Details, 1) {
+		detail := got.DiscrepantTransactions.Details[0]
+		assert.Equal(t, "SYS002", detail.SystemTransaction.TrxID)
+		assert.Nil(t, detail.SystemFX)
+		assert.Equal(t, &domain.FXConversion{
+			From:            "USD",
+			To:              "IDR",
+			Rate:            domain.MustParseDecimal("16450.50"),
+			RateDate:        "2025-09-01",
+			OriginalAmount:  domain.MustParseDecimal("199.99"),
+			ConvertedAmount: domain.MustParseDecimal("3289935.50"),
+		}, detail.BankFX)
+	}
+
+	t.Run("missing FX source", func(t *testing.T) {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), namedSource("system.csv")).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), namedSources("usd.csv")).Return(bankTxs, nil)
+
+		_, err := usecase.NewReconciliationUseCase(repo).Reconcile(context.Background(), namedSource("system.csv"), namedSources("usd.csv"), day, day)
+		assert.ErrorContains(t, err, "no FX rate source configured")
+	})
+}
+
+func TestReconciliationUseCase_Reconcile_RepeatedBankIdentifiers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "S1", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(10 * time.Hour)},
+	}
+	// Both statements number their rows from 1
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "1", Amount: domain.MustParseDecimal("100"), NormalizedAmount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "bank_a.csv"},
+		{UniqueIdentifier: "1", Amount: domain.MustParseDecimal("999"), NormalizedAmount: domain.MustParseDecimal("999"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "bank_b.csv"},
+	}
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+	got, err := usecase.NewReconciliationUseCase(repo).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank_a.csv", "bank_b.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, got.ReconciliationSummary.MatchedTransactions)
+	assert.Equal(t, 0, got.DiscrepantTransactions.Count)
+	assert.Empty(t, got.UnmatchedTransactions.SystemMissingFromBank)
+	assert.Equal(t, map[string][]domain.BankTransaction{"bank_b.csv": {bankTxs[1]}}, got.UnmatchedTransactions.BankMissingFromSystem)
+
+	streamer := mock_usecase.NewMockTransactionStreamer(ctrl)
+	streamer.EXPECT().StreamSystemTransactions(gomock.Any(), gomock.Any(), day, day).Return(domain.SliceStream(systemTxs), nil)
+	streamer.EXPECT().StreamBankTransactions(gomock.Any(), gomock.Any(), day, day).Return(domain.SliceStream(bankTxs), nil)
+	partitioned, err := usecase.NewReconciliationUseCase(nil, usecase.WithDatePartitioning(streamer)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank_a.csv", "bank_b.csv"), day, day)
+	if assert.NoError(t, err) {
+		assert.Equal(t, got, partitioned)
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_SettlementLag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	friday := time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)
+	start, end := friday.AddDate(0, 0, -4), friday.AddDate(0, 0, 4)
+
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, TransactionTime: friday.Add(23*time.Hour + 50*time.Minute)},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeCredit, TransactionTime: friday.AddDate(0, 0, -3)},
+	}
+	bankTxs := []domain.BankTransaction{
+		// Booked the following Monday
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, Date: friday.AddDate(0, 0, 3), BankSource: "Bank1"},
+		// Two candidates for SYS002: one and two days later
+		{UniqueIdentifier: "BANK003", NormalizedAmount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeCredit, Date: friday.AddDate(0, 0, -1), BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeCredit, Date: friday.AddDate(0, 0, -2), BankSource: "Bank1"},
+	}
+
+	tests := []struct {
+		name          string
+		opts          []usecase.Option
+		wantMatched   int
+		wantUnmatched int
+		wantLagged    []domain.MatchedPair
+	}{
+		{
+			name:          "no lag requires the same date",
+			wantMatched:   0,
+			wantUnmatched: 5,
+		},
+		{
+			name:          "calendar lag does not bridge the weekend",
+			opts:          []usecase.Option{usecase.WithSettlementLag(1, false)},
+			wantMatched:   1,
+			wantUnmatched: 3,
+			wantLagged: []domain.MatchedPair{
+				{SystemTrxID: "SYS002", BankUniqueIdentifier: "BANK002", BankSource: "Bank1", SystemDate: "2025-09-02", BankDate: "2025-09-03", DayOffset: 1},
+			},
+		},
+		{
+			name:          "business day lag picks the closest date",
+			opts:          []usecase.Option{usecase.WithSettlementLag(2, true)},
+			wantMatched:   2,
+			wantUnmatched: 1,
+			wantLagged: []domain.MatchedPair{
+				{SystemTrxID: "SYS001", BankUniqueIdentifier: "BANK001", BankSource: "Bank1", SystemDate: "2025-09-05", BankDate: "2025-09-08", DayOffset: 3},
+				{SystemTrxID: "SYS002", BankUniqueIdentifier: "BANK002", BankSource: "Bank1", SystemDate: "2025-09-02", BankDate: "2025-09-03", DayOffset: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+			got, err := usecase.NewReconciliationUseCase(repo, tt.opts...).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), start, end)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantMatched, got.ReconciliationSummary.MatchedTransactions)
+			assert.Equal(t, tt.wantUnmatched, got.UnmatchedTransactions.Count)
+			assert.ElementsMatch(t, tt.wantLagged, got.LaggedMatches)
+		})
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_AmountTolerance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("1000000"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(9 * time.Hour)},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("120.25"), Type: domain.TransactionTypeDebit, TransactionTime: day.Add(10 * time.Hour)},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("500000"), Type: domain.TransactionTypeDebit, TransactionTime: day.Add(11 * time.Hour)},
+	}
+	bankTxs := []domain.BankTransaction{
+		// Credited 1,000,000 less a 6,500 transfer fee
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("993500"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "bank_A.csv"},
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("120.30"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "bank_B.csv"},
+		// Debited 500,000 plus a 6,500 fee
+		{UniqueIdentifier: "BANK003", NormalizedAmount: domain.MustParseDecimal("506500"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "bank_A.csv"},
+	}
+
+	tests := []struct {
+		name        string
+		opts        []usecase.Option
+		wantMatched int
+		wantReasons map[string]domain.DiscrepancyReason
+	}{
+		{
+			name:        "disabled by default",
+			wantMatched: 0,
+			wantReasons: map[string]domain.DiscrepancyReason{},
+		},
+		{
+			name: "absolute tolerance only",
+			opts: []usecase.Option{
+				usecase.WithMatchers(append(usecase.DefaultMatchers(),
+					usecase.ToleranceMatcher(domain.MustParseDecimal("0.10"), domain.Decimal{}))...),
+			},
+			wantMatched: 1,
+			wantReasons: map[string]domain.DiscrepancyReason{"SYS002": domain.DiscrepancyWithinTolerance},
+		},
+		{
+			name: "fee schedule per bank",
+			opts: []usecase.Option{
+				usecase.WithMatchers(append(usecase.DefaultMatchers(),
+					usecase.ToleranceMatcher(domain.MustParseDecimal("0.10"), domain.Decimal{},
+						usecase.FeeRule{BankSource: "bank_A*", Fixed: domain.MustParseDecimal("6500")}))...),
+			},
+			wantMatched: 3,
+			wantReasons: map[string]domain.DiscrepancyReason{
+				"SYS001": domain.DiscrepancyFeeDeducted,
+				"SYS002": domain.DiscrepancyWithinTolerance,
+				"SYS003": domain.DiscrepancyFeeDeducted,
+			},
+		},
+		{
+			// A statement parsed with a profile without a fee takes no fee from later patterns
+			name: "first fee rule applying wins",
+			opts: []usecase.Option{
+				usecase.WithMatchers(append(usecase.DefaultMatchers(),
+					usecase.ToleranceMatcher(domain.MustParseDecimal("0.10"), domain.Decimal{},
+						usecase.FeeRule{BankSource: "bank_A.csv"},
+						usecase.FeeRule{BankSource: "bank_A*", Fixed: domain.MustParseDecimal("6500")}))...),
+			},
+			wantMatched: 1,
+			wantReasons: map[string]domain.DiscrepancyReason{"SYS002": domain.DiscrepancyWithinTolerance},
+		},
+		{
+			name: "percentage tolerance",
+			opts: []usecase.Option{
+				usecase.WithMatchers(append(usecase.DefaultMatchers(),
+					usecase.ToleranceMatcher(domain.Decimal{}, domain.MustParseDecimal("1.5")))...),
+			},
+			wantMatched: 3,
+			wantReasons: map[string]domain.DiscrepancyReason{
+				"SYS001": domain.DiscrepancyWithinTolerance,
+				"SYS002": domain.DiscrepancyWithinTolerance,
+				"SYS003": domain.DiscrepancyWithinTolerance,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+			got, err := usecase.NewReconciliationUseCase(repo, tt.opts...).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantMatched, got.ReconciliationSummary.MatchedTransactions)
+			assert.Equal(t, len(systemTxs)+len(bankTxs)-2*tt.wantMatched, got.UnmatchedTransactions.Count)
+
+			reasons := make(map[string]domain.DiscrepancyReason)
+			for _, d := range got.DiscrepantTransactions.Details {
+				reasons[d.SystemTransaction.TrxID] = d.Reason
+			}
+			assert.Equal(t, tt.wantReasons, reasons)
+		})
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_AggregateMatching(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(9 * time.Hour)},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("250.50"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(10 * time.Hour)},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(11 * time.Hour)},
+		{TrxID: "SYS004", Amount: domain.MustParseDecimal("49.50"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(12 * time.Hour)},
+		{TrxID: "SYS005", Amount: domain.MustParseDecimal("300"), Type: domain.TransactionTypeDebit, TransactionTime: day.Add(13 * time.Hour)},
+	}
+	bankTxs := []domain.BankTransaction{
+		// Gateway settlement of SYS001 + SYS002 + SYS004
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("400"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "Bank1"},
+		// SYS005 paid out in two debits
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("120"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK003", NormalizedAmount: domain.MustParseDecimal("180"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "Bank1"},
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+		got, err := usecase.NewReconciliationUseCase(repo).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, got.GroupedMatches.Count)
+		assert.Equal(t, 8, got.UnmatchedTransactions.Count)
+	})
+
+	t.Run("many-to-one and one-to-many", func(t *testing.T) {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+		got, err := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(append(usecase.DefaultMatchers(), usecase.AggregateMatcher(4))...)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 2, got.GroupedMatches.Count)
+		assert.Equal(t, 0, got.ReconciliationSummary.MatchedTransactions)
+		assert.Equal(t, 4, got.ReconciliationSummary.GroupedSystemTransactions)
+		assert.Equal(t, 3, got.ReconciliationSummary.GroupedBankTransactions)
+		assert.Equal(t, 1, got.UnmatchedTransactions.Count)
+		// Every processed transaction is matched, grouped or unmatched
+		summary := got.ReconciliationSummary
+		assert.Equal(t, summary.TotalSystemTransactionsProcessed, summary.MatchedTransactions+summary.GroupedSystemTransactions+len(got.UnmatchedTransactions.SystemMissingFromBank))
+		assert.Equal(t, summary.TotalBankTransactionsProcessed, summary.MatchedTransactions+summary.GroupedBankTransactions+len(got.UnmatchedTransactions.BankMissingFromSystem["Bank1"]))
+		assert.Equal(t, "SYS003", got.UnmatchedTransactions.SystemMissingFromBank[0].TrxID)
+
+		if assert.Len(t, got.GroupedMatches.Details, 2) {
+			settlement := got.GroupedMatches.Details[0]
+			assert.Equal(t, domain.MustParseDecimal("400"), settlement.Total)
+			assert.Equal(t, []string{"SYS001", "SYS002", "SYS004"}, systemIDs(settlement.SystemTransactions))
+			assert.Len(t, settlement.BankTransactions, 1)
+
+			payout := got.GroupedMatches.Details[1]
+			assert.Equal(t, domain.MustParseDecimal("300"), payout.Total)
+			assert.Equal(t, []string{"SYS005"}, systemIDs(payout.SystemTransactions))
+			assert.Len(t, payout.BankTransactions, 2)
+		}
+	})
+
+	t.Run("group size bound", func(t *testing.T) {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+		got, err := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(append(usecase.DefaultMatchers(), usecase.AggregateMatcher(2))...)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+		assert.NoError(t, err)
+		// The three-way settlement is out of reach; the two-way payout is still found
+		assert.Equal(t, 1, got.GroupedMatches.Count)
+	})
+}
+
+func systemIDs(txs []domain.SystemTransaction) []string {
+	ids := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.TrxID)
+	}
+	return ids
+}
+
+func TestReconciliationUseCase_Reconcile_ReferencePatterns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("101"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("102"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("103"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS004", Amount: domain.MustParseDecimal("104"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "A1", NormalizedAmount: domain.MustParseDecimal("1"), Type: domain.TransactionTypeDebit, Date: day, Description: "PAYMENT TRXID:sys001", BankSource: "bank_a_sep.csv"},
+		{UniqueIdentifier: "A2", NormalizedAmount: domain.MustParseDecimal("2"), Type: domain.TransactionTypeDebit, Date: day, Description: "TRF REF SYS002 ACME", BankSource: "bank_a_sep.csv"},
+		{UniqueIdentifier: "A3", NormalizedAmount: domain.MustParseDecimal("3"), Type: domain.TransactionTypeDebit, Date: day, Description: "TRANSFER", BankSource: "bank_a_sep.csv", ReferenceFields: []string{" sys003 "}},
+		{UniqueIdentifier: "B1", NormalizedAmount: domain.MustParseDecimal("4"), Type: domain.TransactionTypeDebit, Date: day, Description: "trxID:SYS0040", BankSource: "bank_b_sep.csv"},
+		{UniqueIdentifier: "B2", NormalizedAmount: domain.MustParseDecimal("4"), Type: domain.TransactionTypeDebit, Date: day, Description: "REF SYS004", BankSource: "bank_b_sep.csv"},
+	}
+
+	rule, err := usecase.NewReferenceRule("bank_a*.csv", `REF\s+(SYS\d+)`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name  string
+		rules []usecase.ReferenceRule
+		want  map[string]string // bank ID -> system ID
+	}{
+		{
+			name: "default pattern only",
+			want: map[string]string{"A1": "SYS001", "A3": "SYS003"},
+		},
+		{
+			name:  "bank rule",
+			rules: []usecase.ReferenceRule{rule},
+			want:  map[string]string{"A1": "SYS001", "A2": "SYS002", "A3": "SYS003"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+			got, err := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(usecase.ReferenceMatcher(tt.rules...))).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+			if !assert.NoError(t, err) {
+				return
+			}
+			matched := make(map[string]string)
+			for _, d := range got.DiscrepantTransactions.Details {
+				matched[d.BankTransaction.UniqueIdentifier] = d.SystemTransaction.TrxID
+			}
+			assert.Equal(t, tt.want, matched)
+			assert.Equal(t, len(tt.want), got.ReconciliationSummary.MatchedTransactions)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := usecase.NewReferenceRule("bank_a*.csv", `REF (SYS`)
+		assert.ErrorContains(t, err, "invalid reference pattern")
+	})
+}
+
+func TestReconciliationUseCase_Reconcile_UnequalGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	amount := domain.MustParseDecimal("75")
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: amount, Type: domain.TransactionTypeDebit, TransactionTime: day.Add(9 * time.Hour)},
+		{TrxID: "SYS002", Amount: amount, Type: domain.TransactionTypeDebit, TransactionTime: day.Add(10 * time.Hour)},
+		{TrxID: "SYS003", Amount: amount, Type: domain.TransactionTypeDebit, TransactionTime: day.Add(11 * time.Hour)},
+		{TrxID: "SYS004", Amount: amount, Type: domain.TransactionTypeCredit, TransactionTime: day.Add(12 * time.Hour)},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK001", NormalizedAmount: amount, Type: domain.TransactionTypeDebit, Date: day, Description: "TRANSFER", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK002", NormalizedAmount: amount, Type: domain.TransactionTypeDebit, Date: day, Description: "transfer sys003", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK003", NormalizedAmount: amount, Type: domain.TransactionTypeCredit, Date: day, Description: "TOPUP", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK004", NormalizedAmount: amount, Type: domain.TransactionTypeCredit, Date: day, Description: "TOPUP", BankSource: "Bank2"},
+	}
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+	got, err := usecase.NewReconciliationUseCase(repo).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 3, got.ReconciliationSummary.MatchedTransactions)
+	assert.Equal(t, []domain.MatchedPair{
+		// Three debits against two: the reference hint wins, then the nearest timestamp
+		{SystemTrxID: "SYS001", BankUniqueIdentifier: "BANK001", BankSource: "Bank1", SystemDate: "2025-09-01", BankDate: "2025-09-01", Ambiguous: true},
+		{SystemTrxID: "SYS003", BankUniqueIdentifier: "BANK002", BankSource: "Bank1", SystemDate: "2025-09-01", BankDate: "2025-09-01", Ambiguous: true},
+		// One credit against two
+		{SystemTrxID: "SYS004", BankUniqueIdentifier: "BANK003", BankSource: "Bank1", SystemDate: "2025-09-01", BankDate: "2025-09-01", Ambiguous: true},
+	}, got.AmbiguousMatches)
+
+	assert.Equal(t, 2, got.UnmatchedTransactions.Count)
+	assert.Equal(t, []string{"SYS002"}, systemIDs(got.UnmatchedTransactions.SystemMissingFromBank))
+	assert.Len(t, got.UnmatchedTransactions.BankMissingFromSystem["Bank2"], 1)
+}
+
+func TestReconciliationUseCase_Reconcile_UnequalGroupsAtScale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// A payroll day: many transfers of one amount, a few not yet on the statement
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	amount := domain.MustParseDecimal("5000000")
+	var systemTxs []domain.SystemTransaction
+	var bankTxs []domain.BankTransaction
+	for i := 0; i < 5000; i++ {
+		systemTxs = append(systemTxs, domain.SystemTransaction{TrxID: fmt.Sprintf("PAY%05d", i), Amount: amount, Type: domain.TransactionTypeDebit, TransactionTime: day.Add(time.Duration(i) * time.Second)})
+		if i < 4990 {
+			bankTxs = append(bankTxs, domain.BankTransaction{UniqueIdentifier: fmt.Sprintf("B%05d", i), NormalizedAmount: amount, Type: domain.TransactionTypeDebit, Date: day, Description: "SALARY", BankSource: "bank.csv"})
+		}
+	}
+	// Mentioning PAY04999 is not mentioning PAY0499
+	bankTxs[0].Description = "SALARY PAY04999"
+	systemTxs = append(systemTxs, domain.SystemTransaction{TrxID: "PAY0499", Amount: amount, Type: domain.TransactionTypeDebit, TransactionTime: day})
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+	got, err := usecase.NewReconciliationUseCase(repo).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 4990, got.ReconciliationSummary.MatchedTransactions)
+	matched := make(map[string]string)
+	for _, pair := range got.AmbiguousMatches {
+		matched[pair.BankUniqueIdentifier] = pair.SystemTrxID
+	}
+	assert.Equal(t, "PAY04999", matched["B00000"])
+	// The rest pair up by nearest timestamp, leaving the latest transfers unmatched
+	assert.Equal(t, "PAY00000", matched["B00001"])
+	assert.Equal(t, "PAY0499", matched["B00002"])
+	assert.Len(t, got.UnmatchedTransactions.SystemMissingFromBank, 11)
+}
+
+func TestReconciliationUseCase_Reconcile_MatchExplanations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, TransactionTime: day.Add(9 * time.Hour)},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("200"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(10 * time.Hour)},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(11 * time.Hour)},
+		{TrxID: "SYS004", Amount: domain.MustParseDecimal("50"), Type: domain.TransactionTypeCredit, TransactionTime: day.Add(12 * time.Hour)},
+		{TrxID: "SYS005", Amount: domain.MustParseDecimal("99"), Type: domain.TransactionTypeDebit, TransactionTime: day.Add(13 * time.Hour)},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, Date: day, Description: "trxID:SYS001", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("200"), Type: domain.TransactionTypeCredit, Date: day.AddDate(0, 0, 1), BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK003", NormalizedAmount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK004", NormalizedAmount: domain.MustParseDecimal("98.50"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "Bank2"},
+	}
+	matchers := append(usecase.DefaultMatchers(), usecase.AggregateMatcher(2), usecase.ToleranceMatcher(domain.NewDecimalFromInt(1), domain.Decimal{}))
+
+	t.Run("disabled by default", func(t *testing.T) {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+		got, err := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(matchers...)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Nil(t, got.MatchedTransactions)
+	})
+
+	t.Run("every pair explained", func(t *testing.T) {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+		uc := usecase.NewReconciliationUseCase(repo,
+			usecase.WithSettlementLag(1, false),
+			usecase.WithMatchers(matchers...),
+			usecase.WithMatchExplanations(),
+		)
+		got, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day.AddDate(0, 0, 1))
+		if !assert.NoError(t, err) || !assert.NotNil(t, got.MatchedTransactions) {
+			return
+		}
+
+		type explanation struct {
+			sys, bank, pass, key string
+			confidence           float64
+		}
+		var explained []explanation
+		for _, d := range got.MatchedTransactions.Details {
+			explained = append(explained, explanation{d.SystemTrxID, d.BankUniqueIdentifier, d.Pass, d.Key, d.Confidence})
+		}
+		assert.Equal(t, []explanation{
+			{"SYS001", "BANK001", "reference", "SYS001", 1},
+			{"SYS002", "BANK002", "exact", "CREDIT-200@2025-09-01", 0.9},
+			{"SYS003", "BANK003", "aggregate", "CREDIT-150 sum of 2 system transactions", 0.6},
+			{"SYS004", "BANK003", "aggregate", "CREDIT-150 sum of 2 system transactions", 0.6},
+			{"SYS005", "BANK004", "tolerance", "DEBIT-99~98.5", 0.6},
+		}, explained)
+		assert.Equal(t, 5, got.MatchedTransactions.Count)
+		assert.Equal(t, 1, got.MatchedTransactions.Details[1].DayOffset)
+	})
+}
+
+func TestReconciliationUseCase_Reconcile_Overrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("200"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("300"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, Date: day, Description: "trxID:SYS001", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("95"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK003", NormalizedAmount: domain.MustParseDecimal("300"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK004", NormalizedAmount: domain.MustParseDecimal("1"), Type: domain.TransactionTypeDebit, Date: day, Description: "FEE", BankSource: "Bank1"},
+	}
+	overrides := []domain.Override{
+		// Takes precedence over the reference quoted by BANK001
+		{Action: domain.OverrideMatch, SystemTrxID: "SYS001", BankUniqueIdentifier: "BANK002", Note: "ticket 42"},
+		{Action: domain.OverrideIgnore, BankUniqueIdentifier: "BANK004"},
+		{Action: domain.OverrideMatch, SystemTrxID: "SYS001", BankUniqueIdentifier: "BANK003"},
+		{Action: domain.OverrideMatch, SystemTrxID: "SYS999", BankUniqueIdentifier: "BANK003"},
+		{Action: domain.OverrideIgnore, BankUniqueIdentifier: "BANK003", BankSource: "Bank2"},
+	}
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+	uc := usecase.NewReconciliationUseCase(repo, usecase.WithOverrides(overrides), usecase.WithMatchExplanations())
+	got, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []domain.MatchedPair{
+		{SystemTrxID: "SYS001", BankUniqueIdentifier: "BANK002", BankSource: "Bank1", SystemDate: "2025-09-01", BankDate: "2025-09-01", Manual: true},
+	}, got.ManualMatches)
+	assert.Equal(t, "manual", got.MatchedTransactions.Details[0].Pass)
+	assert.Equal(t, "ticket 42", got.MatchedTransactions.Details[0].Key)
+
+	// The manual pair still reports its amount difference
+	assert.Equal(t, 1, got.DiscrepantTransactions.Count)
+	assert.Equal(t, domain.MustParseDecimal("5"), got.DiscrepantTransactions.TotalDiscrepancyValue)
+
+	if assert.NotNil(t, got.IgnoredTransactions) {
+		assert.Equal(t, 1, got.IgnoredTransactions.Count)
+		assert.Equal(t, "BANK004", got.IgnoredTransactions.BankTransactions[0].UniqueIdentifier)
+	}
+
+	assert.Equal(t, []domain.OverrideError{
+		{Override: overrides[2], Error: "system transaction SYS001 is already overridden"},
+		{Override: overrides[3], Error: "system transaction SYS999 not found in the reconciliation timeframe"},
+		{Override: overrides[4], Error: "bank transaction BANK003 not found in the reconciliation timeframe"},
+	}, got.OverrideErrors)
+
+	// SYS003 still matches automatically; BANK001 loses its reference match and SYS002 has no counterpart
+	assert.Equal(t, 2, got.ReconciliationSummary.MatchedTransactions)
+	assert.Equal(t, []string{"SYS002"}, systemIDs(got.UnmatchedTransactions.SystemMissingFromBank))
+	assert.Len(t, got.UnmatchedTransactions.BankMissingFromSystem["Bank1"], 1)
+	assert.Equal(t, 2, got.UnmatchedTransactions.Count)
+}
+
+func TestReconciliationUseCase_Reconcile_OpenItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sepStart := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	sepEnd := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+	octStart := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	octEnd := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS029", Amount: domain.MustParseDecimal("10"), Type: domain.TransactionTypeCredit, TransactionTime: sepEnd.AddDate(0, 0, -1)},
+		{TrxID: "SYS030", Amount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeDebit, TransactionTime: sepEnd.Add(20 * time.Hour)},
+		{TrxID: "SYS031", Amount: domain.MustParseDecimal("40"), Type: domain.TransactionTypeDebit, TransactionTime: octStart},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK_OCT_1", NormalizedAmount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeDebit, Date: octStart.AddDate(0, 0, 1), Description: "trxID:SYS030", BankSource: "Bank1"},
+	}
+
+	var stored domain.OpenItems
+	store := mock_usecase.NewMockOpenItemStore(ctrl)
+	store.EXPECT().Load(gomock.Any()).DoAndReturn(func(ctx context.Context) (domain.OpenItems, error) { return stored, nil }).Times(2)
+	store.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, items domain.OpenItems) error {
+		stored = items
+		return nil
+	}).Times(2)
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil).Times(2)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil).Times(2)
+	uc := usecase.NewReconciliationUseCase(repo, usecase.WithOpenItems(store))
+
+	// September leaves both system transactions open
+	sep, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), sepStart, sepEnd)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &domain.OpenItemsReport{
+		Open: []domain.OpenItemAge{
+			{SystemTrxID: "SYS029", Date: "2025-09-29", FirstSeen: "2025-09-30", AgeDays: 1},
+			{SystemTrxID: "SYS030", Date: "2025-09-30", FirstSeen: "2025-09-30", AgeDays: 0},
+		},
+	}, sep.OpenItems)
+	assert.Equal(t, "2025-09-30", stored.AsOf)
+	assert.Len(t, stored.Items, 2)
+
+	// October clears SYS030 against its bank booking; SYS029 stays open and ages
+	oct, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), octStart, octEnd)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, oct.ReconciliationSummary.TotalSystemTransactionsProcessed)
+	assert.Equal(t, 1, oct.ReconciliationSummary.MatchedTransactions)
+	assert.Equal(t, &domain.OpenItemsReport{
+		CarriedIn: 2,
+		Cleared:   1,
+		Open: []domain.OpenItemAge{
+			{SystemTrxID: "SYS029", Date: "2025-09-29", FirstSeen: "2025-09-30", AgeDays: 32, CarriedForward: true},
+			{SystemTrxID: "SYS031", Date: "2025-10-01", FirstSeen: "2025-10-31", AgeDays: 30},
+		},
+	}, oct.OpenItems)
+	assert.Equal(t, []string{"SYS029", "SYS031"}, systemIDs(oct.UnmatchedTransactions.SystemMissingFromBank))
+	assert.Equal(t, "2025-10-31", stored.AsOf)
+}
+
+func TestReconciliationUseCase_Reconcile_OpenItemsAcrossPeriods(t *testing.T) {
+	sepStart := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	sepEnd := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+	octStart := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	octEnd := time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	sepSystem := []domain.SystemTransaction{
+		// Far from the bank credit of the same amount, so the two stay open
+		{TrxID: "SYS101", Amount: domain.MustParseDecimal("10"), Type: domain.TransactionTypeCredit, TransactionTime: sepStart.AddDate(0, 0, 4)},
+		// Settled on the second day of October, three days later, without quoting an ID
+		{TrxID: "SYS100", Amount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeDebit, TransactionTime: sepEnd.AddDate(0, 0, -1)},
+	}
+	sepBank := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK_SEP_1", NormalizedAmount: domain.MustParseDecimal("10"), Type: domain.TransactionTypeCredit, Date: sepEnd.AddDate(0, 0, -5), BankSource: "Bank1"},
+		// Quotes a system transaction recorded in October in a reference column
+		{UniqueIdentifier: "BANK_SEP_2", NormalizedAmount: domain.MustParseDecimal("54"), Type: domain.TransactionTypeCredit, Date: sepEnd, BankSource: "Bank1", ReferenceFields: []string{"SYS102"}},
+	}
+	octSystem := []domain.SystemTransaction{
+		{TrxID: "SYS102", Amount: domain.MustParseDecimal("55"), Type: domain.TransactionTypeCredit, TransactionTime: octStart.Add(9 * time.Hour)},
+	}
+	octBank := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK_OCT_1", NormalizedAmount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeDebit, Date: octStart.AddDate(0, 0, 1), BankSource: "Bank1"},
+	}
+
+	check := func(t *testing.T, ctrl *gomock.Controller, repo usecase.TransactionRepository, opts ...usecase.Option) {
+		var stored domain.OpenItems
+		store := mock_usecase.NewMockOpenItemStore(ctrl)
+		store.EXPECT().Load(gomock.Any()).DoAndReturn(func(ctx context.Context) (domain.OpenItems, error) { return stored, nil }).Times(2)
+		store.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, items domain.OpenItems) error {
+			// Like the open items file, keep reference fields only beside the transaction
+			for _, item := range items.Items {
+				if item.Bank != nil {
+					item.Bank.ReferenceFields = nil
+				}
+			}
+			stored = items
+			return nil
+		}).Times(2)
+		uc := usecase.NewReconciliationUseCase(repo, append(opts, usecase.WithSettlementLag(1, false), usecase.WithOpenItems(store))...)
+
+		_, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), sepStart, sepEnd)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, stored.Items, 4)
+
+		// SYS100 clears across the gap between the periods and SYS102 on the stored
+		// reference; SYS101 and BANK_SEP_1 are still too far apart
+		oct, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), octStart, octEnd)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 2, oct.ReconciliationSummary.MatchedTransactions)
+		if assert.Len(t, oct.DiscrepantTransactions.Details, 1) {
+			assert.Equal(t, "SYS102", oct.DiscrepantTransactions.Details[0].SystemTransaction.TrxID)
+			assert.Equal(t, "BANK_SEP_2", oct.DiscrepantTransactions.Details[0].BankTransaction.UniqueIdentifier)
+		}
+		assert.Equal(t, 2, oct.OpenItems.Cleared)
+		assert.Equal(t, []string{"SYS101"}, systemIDs(oct.UnmatchedTransactions.SystemMissingFromBank))
+		if assert.Len(t, oct.UnmatchedTransactions.BankMissingFromSystem["Bank1"], 1) {
+			assert.Equal(t, "BANK_SEP_1", oct.UnmatchedTransactions.BankMissingFromSystem["Bank1"][0].UniqueIdentifier)
+		}
+	}
+
+	t.Run("in memory", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(append(sepSystem, octSystem...), nil).Times(2)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(append(sepBank, octBank...), nil).Times(2)
+		check(t, ctrl, repo)
+	})
+
+	t.Run("by date", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		streamer := mock_usecase.NewMockTransactionStreamer(ctrl)
+		streamer.EXPECT().StreamSystemTransactions(gomock.Any(), gomock.Any(), sepStart, sepEnd).Return(domain.SliceStream(sepSystem), nil)
+		streamer.EXPECT().StreamBankTransactions(gomock.Any(), gomock.Any(), sepStart, sepEnd).Return(domain.SliceStream(sepBank), nil)
+		streamer.EXPECT().StreamSystemTransactions(gomock.Any(), gomock.Any(), octStart, octEnd).Return(domain.SliceStream(octSystem), nil)
+		streamer.EXPECT().StreamBankTransactions(gomock.Any(), gomock.Any(), octStart, octEnd).Return(domain.SliceStream(octBank), nil)
+		check(t, ctrl, nil, usecase.WithDatePartitioning(streamer))
+	})
+}
+
+func TestReconciliationUseCase_Reconcile_OpenItemsErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		setup   func(store *mock_usecase.MockOpenItemStore)
+		wantErr string
+	}{
+		{
+			name: "load fails",
+			setup: func(store *mock_usecase.MockOpenItemStore) {
+				store.EXPECT().Load(gomock.Any()).Return(domain.OpenItems{}, errors.New("corrupt"))
+			},
+			wantErr: "could not load open items: corrupt",
+		},
+		{
+			name: "save fails",
+			setup: func(store *mock_usecase.MockOpenItemStore) {
+				store.EXPECT().Load(gomock.Any()).Return(domain.OpenItems{}, nil)
+				store.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("disk full"))
+			},
+			wantErr: "could not save open items: disk full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(nil, nil)
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(nil, nil)
+			store := mock_usecase.NewMockOpenItemStore(ctrl)
+			tt.setup(store)
+
+			got, err := usecase.NewReconciliationUseCase(repo, usecase.WithOpenItems(store)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_IngestionErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), namedSource("system.csv")).Return([]domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+	}, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), namedSources("bank.csv")).Return([]domain.BankTransaction{
+		{UniqueIdentifier: "B1", Amount: domain.MustParseDecimal("-100"), NormalizedAmount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "bank.csv"},
+	}, nil)
+	source := mock_usecase.NewMockIngestionErrorSource(ctrl)
+	source.EXPECT().IngestionErrors().Return([]domain.IngestionError{
+		{File: "system.csv", Line: 9, Column: "amount", Value: "1O0", Reason: `invalid decimal "1O0"`},
+		{File: "bank.csv", Line: 3, Reason: "wrong number of fields"},
+		{File: "bank.csv", Line: 2, Column: "date", Value: "31/02", Reason: "bad date"},
+	})
+
+	got, err := usecase.NewReconciliationUseCase(repo, usecase.WithIngestionErrors(source)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 0, got.UnmatchedTransactions.Count)
+	assert.Equal(t, &domain.IngestionErrors{
+		Count: 3,
+		Details: []domain.IngestionError{
+			{File: "bank.csv", Line: 2, Column: "date", Value: "31/02", Reason: "bad date"},
+			{File: "bank.csv", Line: 3, Reason: "wrong number of fields"},
+			{File: "system.csv", Line: 9, Column: "amount", Value: "1O0", Reason: `invalid decimal "1O0"`},
+		},
+	}, got.IngestionErrors)
+
+	t.Run("omitted when strict", func(t *testing.T) {
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(nil, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(nil, nil)
+		got, err := usecase.NewReconciliationUseCase(repo).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+		assert.NoError(t, err)
+		assert.Nil(t, got.IngestionErrors)
+	})
+}
+
+func TestReconciliationUseCase_Reconcile_Balances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("200.50"), Type: domain.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "SYS003", Amount: domain.MustParseDecimal("500"), Type: domain.TransactionTypeCredit, TransactionTime: day},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "A1", Amount: domain.MustParseDecimal("-150"), NormalizedAmount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, Date: day, BankSource: "bank_a.csv"},
+		{UniqueIdentifier: "A2", Amount: domain.MustParseDecimal("200.50"), NormalizedAmount: domain.MustParseDecimal("200.50"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "bank_a.csv"},
+		{UniqueIdentifier: "B1", Amount: domain.MustParseDecimal("500"), NormalizedAmount: domain.MustParseDecimal("500"), Type: domain.TransactionTypeCredit, Date: day, BankSource: "bank_b.csv"},
+	}
+	statements := []domain.StatementBalance{
+		{BankSource: "bank_a.csv", Opening: decimalPtr("1000"), Closing: decimalPtr("1050.50")},
+		{BankSource: "bank_b.csv", Opening: decimalPtr("0"), Closing: decimalPtr("450")},
+	}
+	inIDR := func(sb domain.StatementBalance) domain.StatementBalance {
+		sb.Currency = "IDR"
+		return sb
+	}
+	dec := domain.MustParseDecimal
+
+	tests := []struct {
+		name   string
+		opts   []usecase.Option
+		want   *domain.Balances
+		noRepo bool
+	}{
+		{
+			name:   "disabled by default",
+			noRepo: true,
+		},
+		{
+			name: "statement balances",
+			opts: []usecase.Option{usecase.WithBalanceReconciliation()},
+			want: &domain.Balances{Banks: []domain.BankBalance{
+				{StatementBalance: inIDR(statements[0]), Movements: dec("50.5"), ExpectedClosing: decimalPtr("1050.5"), Difference: decimalPtr("0"), Balanced: boolPtr(true)},
+				{StatementBalance: inIDR(statements[1]), Movements: dec("500"), ExpectedClosing: decimalPtr("500"), Difference: decimalPtr("-50"), Balanced: boolPtr(false)},
+			}},
+		},
+		{
+			name: "given balances take precedence and ledger is compared",
+			opts: []usecase.Option{
+				usecase.WithBalanceReconciliation(domain.StatementBalance{BankSource: "bank_b.csv", Closing: decimalPtr("500")}),
+				usecase.WithLedgerOpeningBalance(dec("999.50")),
+			},
+			want: &domain.Balances{
+				Banks: []domain.BankBalance{
+					{StatementBalance: inIDR(statements[0]), Movements: dec("50.5"), ExpectedClosing: decimalPtr("1050.5"), Difference: decimalPtr("0"), Balanced: boolPtr(true)},
+					{StatementBalance: domain.StatementBalance{BankSource: "bank_b.csv", Currency: "IDR", Opening: decimalPtr("0"), Closing: decimalPtr("500")}, Movements: dec("500"), ExpectedClosing: decimalPtr("500"), Difference: decimalPtr("0"), Balanced: boolPtr(true)},
+				},
+				Ledger: &domain.LedgerBalance{
+					Currency:         "IDR",
+					Opening:          dec("999.5"),
+					Movements:        dec("550.5"),
+					ExpectedClosing:  dec("1550"),
+					BankClosingTotal: decimalPtr("1550.5"),
+					Difference:       decimalPtr("0.5"),
+					Balanced:         boolPtr(false),
+				},
+			},
+		},
+		{
+			name: "missing balances are neither balanced nor unbalanced",
+			opts: []usecase.Option{
+				usecase.WithBalanceReconciliation(domain.StatementBalance{BankSource: "bank_c.csv", Opening: decimalPtr("7")}),
+				usecase.WithLedgerOpeningBalance(dec("0")),
+			},
+			want: &domain.Balances{
+				Banks: []domain.BankBalance{
+					{StatementBalance: inIDR(statements[0]), Movements: dec("50.5"), ExpectedClosing: decimalPtr("1050.5"), Difference: decimalPtr("0"), Balanced: boolPtr(true)},
+					{StatementBalance: inIDR(statements[1]), Movements: dec("500"), ExpectedClosing: decimalPtr("500"), Difference: decimalPtr("-50"), Balanced: boolPtr(false)},
+					{StatementBalance: domain.StatementBalance{BankSource: "bank_c.csv", Currency: "IDR", Opening: decimalPtr("7")}, ExpectedClosing: decimalPtr("7")},
+				},
+				Ledger: &domain.LedgerBalance{Currency: "IDR", Movements: dec("550.5"), ExpectedClosing: dec("550.5")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+			if !tt.noRepo {
+				repo.EXPECT().GetBankBalances(gomock.Any(), namedSources("bank_a.csv", "bank_b.csv")).Return(statements, nil)
+			}
+
+			got, err := usecase.NewReconciliationUseCase(repo, tt.opts...).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank_a.csv", "bank_b.csv"), day, day)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got.ReconciliationSummary.Balances)
+		})
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_BalancesAcrossCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("164505"), Currency: "IDR", Type: domain.TransactionTypeCredit, TransactionTime: day},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "USD_1", Amount: domain.MustParseDecimal("10"), NormalizedAmount: domain.MustParseDecimal("10"), Currency: "USD", Type: domain.TransactionTypeCredit, Date: day, BankSource: "usd.csv"},
+	}
+	statements := []domain.StatementBalance{
+		{BankSource: "idr.csv", Currency: "IDR", Opening: decimalPtr("1000000"), Closing: decimalPtr("1000000")},
+		// In the currency of the statement's transactions
+		{BankSource: "usd.csv", Opening: decimalPtr("90"), Closing: decimalPtr("100")},
+	}
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+	repo.EXPECT().GetBankBalances(gomock.Any(), gomock.Any()).Return(statements, nil)
+	fx := mock_usecase.NewMockFXRateProvider(ctrl)
+	fx.EXPECT().Rate(gomock.Any(), domain.Currency("USD"), domain.Currency("IDR"), gomock.Any()).Return(domain.FXRate{From: "USD", To: "IDR", Date: day, Rate: domain.MustParseDecimal("16450.50")}, nil).AnyTimes()
+
+	uc := usecase.NewReconciliationUseCase(repo,
+		usecase.WithFXRates(fx),
+		usecase.WithBalanceReconciliation(),
+		usecase.WithLedgerOpeningBalance(domain.MustParseDecimal("2480545")),
+	)
+	got, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("idr.csv", "usd.csv"), day, day)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	balances := got.ReconciliationSummary.Balances
+	if !assert.Len(t, balances.Banks, 2) {
+		return
+	}
+	usd := balances.Banks[1]
+	assert.Equal(t, domain.Currency("USD"), usd.Currency)
+	assert.Equal(t, boolPtr(true), usd.Balanced)
+	if assert.NotNil(t, usd.ClosingFX) {
+		assert.Equal(t, domain.MustParseDecimal("1645050"), usd.ClosingFX.ConvertedAmount)
+	}
+	// 1,000,000 IDR plus 100 USD at 16,450.50 against 2,480,545 + 164,505 IDR
+	assert.Equal(t, decimalPtr("2645050"), balances.Ledger.BankClosingTotal)
+	assert.Equal(t, boolPtr(true), balances.Ledger.Balanced)
+}
+
+func TestReconciliationUseCase_Reconcile_BalancesWithoutRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	// Neither closing balance can be converted; the first statement by name is reported
+	statements := []domain.StatementBalance{
+		{BankSource: "usd.csv", Currency: "USD", Closing: decimalPtr("100")},
+		{BankSource: "eur.csv", Currency: "EUR", Closing: decimalPtr("100")},
+	}
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	repo.EXPECT().GetBankBalances(gomock.Any(), gomock.Any()).Return(statements, nil).AnyTimes()
+
+	uc := usecase.NewReconciliationUseCase(repo, usecase.WithBalanceReconciliation())
+	for i := 0; i < 10; i++ {
+		_, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("usd.csv", "eur.csv"), day, day)
+		assert.ErrorContains(t, err, "closing balance of eur.csv: ")
+	}
+}
+
+func decimalPtr(s string) *domain.Decimal {
+	d := domain.MustParseDecimal(s)
+	return &d
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// descriptionMatcher is a bank-specific matcher pairing a bank transaction with the first
+// system transaction of the same amount whose ID appears in lower case in the description.
+type descriptionMatcher struct{}
+
+func (descriptionMatcher) Name() string { return "description" }
+
+func (descriptionMatcher) Match(ctx context.Context, state *usecase.MatchState) error {
+	for _, bankTx := range state.UnmatchedBank() {
+		for _, sysTx := range state.UnmatchedSystem() {
+			if !state.IsSystemMatched(sysTx) && strings.Contains(bankTx.Description, strings.ToLower(sysTx.TrxID)) {
+				state.Match(sysTx, bankTx, usecase.Evidence{Key: bankTx.Description, Confidence: 0.9})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+type failingMatcher struct{}
+
+func (failingMatcher) Name() string { return "failing" }
+
+func (failingMatcher) Match(ctx context.Context, state *usecase.MatchState) error {
+	return errors.New("boom")
+}
+
+func TestReconciliationUseCase_Reconcile_MatcherPipeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, Date: day, Description: "ref sys002", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("100"), Type: domain.TransactionTypeDebit, Date: day, Description: "ref sys001", BankSource: "Bank1"},
+	}
+
+	tests := []struct {
+		name        string
+		matchers    []usecase.Matcher
+		wantMatched int
+		wantErr     string
+	}{
+		{
+			name:        "empty pipeline matches nothing",
+			matchers:    []usecase.Matcher{},
+			wantMatched: 0,
+		},
+		{
+			name:        "exact alone skips multi-transaction groups",
+			matchers:    []usecase.Matcher{usecase.ExactMatcher()},
+			wantMatched: 0,
+		},
+		{
+			name:        "custom matcher runs before built-in passes",
+			matchers:    append([]usecase.Matcher{descriptionMatcher{}}, usecase.DefaultMatchers()...),
+			wantMatched: 2,
+		},
+		{
+			name:     "matcher errors abort reconciliation",
+			matchers: []usecase.Matcher{usecase.ReferenceMatcher(), failingMatcher{}},
+			wantErr:  "failing matching failed: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+			got, err := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(tt.matchers...)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMatched, got.ReconciliationSummary.MatchedTransactions)
+			assert.Equal(t, 4-2*tt.wantMatched, got.UnmatchedTransactions.Count)
+		})
+	}
+}
+
+// benchmarkTransactions returns n system transactions and n bank transactions quoting
+// them by reference, in reverse order so no pair lines up by position.
+func benchmarkTransactions(n int) ([]domain.SystemTransaction, []domain.BankTransaction) {
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	systemTxs := make([]domain.SystemTransaction, n)
+	bankTxs := make([]domain.BankTransaction, n)
+	for i := 0; i < n; i++ {
+		amount := domain.NewDecimal(int64(i+1)*100, 2)
+		systemTxs[i] = domain.SystemTransaction{
+			TrxID:           fmt.Sprintf("SYS%07d", i),
+			Amount:          amount,
+			Type:            domain.TransactionTypeDebit,
+			TransactionTime: day,
+		}
+		bankTxs[n-1-i] = domain.BankTransaction{
+			UniqueIdentifier: fmt.Sprintf("BANK%07d", i),
+			NormalizedAmount: amount,
+			Type:             domain.TransactionTypeDebit,
+			Date:             day,
+			Description:      fmt.Sprintf("Payment trxID:SYS%07d", i),
+			BankSource:       "bank.csv",
+		}
+	}
+	return systemTxs, bankTxs
+}
+
+// BenchmarkReconcile_ReferenceMatching runs reference matching on growing inputs;
+// ns/op should grow linearly with the number of transactions.
+func BenchmarkReconcile_ReferenceMatching(b *testing.B) {
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			systemTxs, bankTxs := benchmarkTransactions(n)
+			ctrl := gomock.NewController(b)
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil).AnyTimes()
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil).AnyTimes()
+			uc := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(usecase.ReferenceMatcher()))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				report, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if report.ReconciliationSummary.MatchedTransactions != n {
+					b.Fatalf("matched %d of %d transactions", report.ReconciliationSummary.MatchedTransactions, n)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkReconcile_ToleranceMatching runs tolerance matching on growing inputs where no
+// amounts are equal; ns/op should grow close to linearly with the number of transactions.
+func BenchmarkReconcile_ToleranceMatching(b *testing.B) {
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			systemTxs, bankTxs := benchmarkTransactions(n)
+			for i := range bankTxs {
+				bankTxs[i].NormalizedAmount = bankTxs[i].NormalizedAmount.Sub(domain.MustParseDecimal("0.25"))
+			}
+			ctrl := gomock.NewController(b)
+			repo := mock_usecase.NewMockTransactionRepository(ctrl)
+			repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil).AnyTimes()
+			repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil).AnyTimes()
+			uc := usecase.NewReconciliationUseCase(repo, usecase.WithMatchers(usecase.ToleranceMatcher(domain.MustParseDecimal("0.5"), domain.Decimal{})))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				report, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if report.ReconciliationSummary.MatchedTransactions != n {
+					b.Fatalf("matched %d of %d transactions", report.ReconciliationSummary.MatchedTransactions, n)
+				}
+			}
+		})
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_Deterministic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	var systemTxs []domain.SystemTransaction
+	var bankTxs []domain.BankTransaction
+	for i := 0; i < 40; i++ {
+		amount := domain.NewDecimalFromInt(int64(100 * (i%5 + 1)))
+		date := day.AddDate(0, 0, i%3)
+		txType := domain.TransactionTypeDebit
+		if i%2 == 0 {
+			txType = domain.TransactionTypeCredit
+		}
+		systemTxs = append(systemTxs, domain.SystemTransaction{
+			TrxID: fmt.Sprintf("SYS%03d", i), Amount: amount, Type: txType, TransactionTime: date.Add(time.Duration(i%4) * time.Hour),
+		})
+		if i%7 == 0 {
+			continue // missing from the bank
+		}
+		if i%6 == 0 {
+			amount = amount.Add(domain.MustParseDecimal("0.5"))
+		}
+		bankTxs = append(bankTxs, domain.BankTransaction{
+			UniqueIdentifier: fmt.Sprintf("B%03d", i), NormalizedAmount: amount, Type: txType,
+			Date: date.AddDate(0, 0, i%2), BankSource: fmt.Sprintf("bank_%d.csv", i%3),
+		})
+	}
+
+	reconcile := func(systemTxs []domain.SystemTransaction, bankTxs []domain.BankTransaction) string {
+		repo := mock_usecase.NewMockTransactionRepository(ctrl)
+		repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+		repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+
+		matchers := append(usecase.DefaultMatchers(), usecase.ToleranceMatcher(domain.NewDecimalFromInt(1), domain.Decimal{}))
+		uc := usecase.NewReconciliationUseCase(repo, usecase.WithSettlementLag(1, false), usecase.WithMatchers(matchers...))
+		report, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day.AddDate(0, 0, 5))
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		out, err := json.Marshal(report)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return string(out)
+	}
+
+	want := reconcile(systemTxs, bankTxs)
+	rng := rand.New(rand.NewSource(1))
+	for run := 0; run < 50; run++ {
+		shuffledSystem := append([]domain.SystemTransaction(nil), systemTxs...)
+		shuffledBank := append([]domain.BankTransaction(nil), bankTxs...)
+		rng.Shuffle(len(shuffledSystem), func(i, j int) { shuffledSystem[i], shuffledSystem[j] = shuffledSystem[j], shuffledSystem[i] })
+		rng.Shuffle(len(shuffledBank), func(i, j int) { shuffledBank[i], shuffledBank[j] = shuffledBank[j], shuffledBank[i] })
+
+		if got := reconcile(systemTxs, bankTxs); got != want {
+			t.Fatalf("run %d: report differs on identical input:\n got %s\nwant %s", run, got, want)
+		}
+		if got := reconcile(shuffledSystem, shuffledBank); got != want {
+			t.Fatalf("run %d: report depends on input order:\n got %s\nwant %s", run, got, want)
+		}
+	}
+}
+
+func TestRunUseCase_Execute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	report := &domain.ReconciliationReport{ReconciliationSummary: domain.Summary{TimeframeStart: "2025-09-01"}}
+	tests := []struct {
+		name       string
+		ctx        func() context.Context
+		reconcile  usecase.ReconcileFunc
+		wantStatus domain.RunStatus
+		wantError  string
+		wantReport *domain.ReconciliationReport
+	}{
+		{
+			name:       "succeeds",
+			ctx:        context.Background,
+			reconcile:  func(ctx context.Context) (*domain.ReconciliationReport, error) { return report, nil },
+			wantStatus: domain.RunSucceeded,
+			wantReport: report,
+		},
+		{
+			name:       "reconciliation fails",
+			ctx:        context.Background,
+			reconcile:  func(ctx context.Context) (*domain.ReconciliationReport, error) { return nil, errors.New("bad file") },
+			wantStatus: domain.RunFailed,
+			wantError:  "bad file",
+		},
+		{
+			name: "cancelled before starting",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			reconcile: func(ctx context.Context) (*domain.ReconciliationReport, error) {
+				t.Fatal("a cancelled run must not reconcile")
+				return nil, nil
+			},
+			wantStatus: domain.RunFailed,
+			wantError:  "context canceled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mock_usecase.NewMockRunStore(ctrl)
+			store.EXPECT().CreateRun(gomock.Any(), gomock.Any()).Return(nil)
+			gomock.InOrder(
+				store.EXPECT().UpdateRun(gomock.Any(), gomock.Any(), gomock.Nil()).Do(func(_ context.Context, run domain.Run, _ *domain.ReconciliationReport) {
+					assert.Equal(t, domain.RunRunning, run.Status)
+					assert.NotNil(t, run.StartedAt)
+				}).Return(nil),
+				store.EXPECT().UpdateRun(gomock.Any(), gomock.Any(), tt.wantReport).Return(nil),
+			)
+
+			uc := usecase.NewRunUseCase(store)
+			run, err := uc.Submit(context.Background(), domain.RunParameters{Start: "2025-09-01", End: "2025-09-30"}, nil)
+			assert.NoError(t, err)
+			assert.Len(t, run.ID, 32)
+			assert.Equal(t, domain.RunQueued, run.Status)
+
+			run, err = uc.Execute(tt.ctx(), run, tt.reconcile)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, run.Status)
+			assert.Equal(t, tt.wantError, run.Error)
+			assert.NotNil(t, run.FinishedAt)
+		})
+	}
+}
+
+func TestRunUseCase_FailInterrupted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_usecase.NewMockRunStore(ctrl)
+	store.EXPECT().ListRuns(gomock.Any(), 0).Return([]domain.Run{
+		{ID: "queued", Status: domain.RunQueued},
+		{ID: "running", Status: domain.RunRunning},
+		{ID: "done", Status: domain.RunSucceeded},
+	}, nil)
+	var failed []string
+	store.EXPECT().UpdateRun(gomock.Any(), gomock.Any(), gomock.Nil()).Do(func(_ context.Context, run domain.Run, _ *domain.ReconciliationReport) {
+		assert.Equal(t, domain.RunFailed, run.Status)
+		assert.Equal(t, "interrupted by a restart", run.Error)
+		failed = append(failed, run.ID)
+	}).Return(nil).Times(2)
+
+	n, err := usecase.NewRunUseCase(store).FailInterrupted(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"queued", "running"}, failed)
+}
+
+// namedSource names a source for the mock repository, which never opens it.
+func namedSource(name string) domain.Source {
+	return domain.Source{Name: name}
+}
+
+func namedSources(names ...string) []domain.Source {
+	var srcs []domain.Source
+	for _, name := range names {
+		srcs = append(srcs, namedSource(name))
+	}
+	return srcs
+}
+
+func TestReconciliationUseCase_Reconcile_DatePartitioning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	start, end := day, day.AddDate(0, 0, 20)
+	var systemTxs []domain.SystemTransaction
+	var bankTxs []domain.BankTransaction
+	for i := 0; i < 60; i++ {
+		date := day.AddDate(0, 0, i/3)
+		amount := domain.NewDecimalFromInt(int64(100 + i))
+		systemTxs = append(systemTxs, domain.SystemTransaction{
+			TrxID: fmt.Sprintf("SYS%03d", i), Amount: amount, Type: domain.TransactionTypeDebit, TransactionTime: date.Add(time.Hour),
+		})
+		if i%7 == 0 {
+			continue // missing from the bank
+		}
+		bank := domain.BankTransaction{
+			UniqueIdentifier: fmt.Sprintf("B%03d", i), Amount: amount.Neg(), NormalizedAmount: amount, Type: domain.TransactionTypeDebit,
+			Date: date.AddDate(0, 0, i%2), BankSource: fmt.Sprintf("bank_%d.csv", i%3),
+		}
+		if i%5 == 0 {
+			bank.Description = fmt.Sprintf("trxID:SYS%03d", i)
+		}
+		if i%11 == 0 {
+			bank.NormalizedAmount = amount.Add(domain.MustParseDecimal("0.5"))
+		}
+		bankTxs = append(bankTxs, bank)
+	}
+	// A bank transaction with no system transactions within reach
+	bankTxs = append(bankTxs, domain.BankTransaction{
+		UniqueIdentifier: "LATE", Amount: domain.NewDecimalFromInt(5), NormalizedAmount: domain.NewDecimalFromInt(5), Type: domain.TransactionTypeCredit,
+		Date: end, BankSource: "bank_0.csv",
+	})
+
+	opts := []usecase.Option{
+		usecase.WithSettlementLag(1, false),
+		usecase.WithMatchers(append(usecase.DefaultMatchers(), usecase.ToleranceMatcher(domain.NewDecimalFromInt(1), domain.Decimal{}))...),
+		usecase.WithMatchExplanations(),
+	}
+
+	repo := mock_usecase.NewMockTransactionRepository(ctrl)
+	repo.EXPECT().GetSystemTransactions(gomock.Any(), gomock.Any()).Return(systemTxs, nil)
+	repo.EXPECT().GetBankTransactions(gomock.Any(), gomock.Any()).Return(bankTxs, nil)
+	want, err := usecase.NewReconciliationUseCase(repo, opts...).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), start, end)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	streamer := mock_usecase.NewMockTransactionStreamer(ctrl)
+	streamer.EXPECT().StreamSystemTransactions(gomock.Any(), namedSource("system.csv"), start, end).Return(domain.SliceStream(systemTxs), nil)
+	streamer.EXPECT().StreamBankTransactions(gomock.Any(), namedSources("bank.csv"), start, end).Return(domain.SliceStream(sortedByDate(bankTxs)), nil)
+	got, err := usecase.NewReconciliationUseCase(nil, append(opts, usecase.WithDatePartitioning(streamer))...).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), start, end)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want, got)
+	assert.Len(t, got.UnmatchedTransactions.BankMissingFromSystem["bank_0.csv"], 1)
+}
+
+func TestReconciliationUseCase_Reconcile_DatePartitioningWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	start, end := day, day.AddDate(0, 0, 10)
+	systemTxs := []domain.SystemTransaction{
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeCredit, TransactionTime: day.AddDate(0, 0, 1)},
+	}
+	bankTxs := []domain.BankTransaction{
+		{UniqueIdentifier: "BANK001", NormalizedAmount: domain.MustParseDecimal("150"), Type: domain.TransactionTypeDebit, Date: day.AddDate(0, 0, 1), BankSource: "Bank1"},
+		// Quotes SYS002 but is booked too late to be within its window
+		{UniqueIdentifier: "BANK002", NormalizedAmount: domain.MustParseDecimal("75"), Type: domain.TransactionTypeCredit, Date: day.AddDate(0, 0, 5), Description: "trxID:SYS002", BankSource: "Bank1"},
+		{UniqueIdentifier: "BANK003", NormalizedAmount: domain.MustParseDecimal("9"), Type: domain.TransactionTypeCredit, Date: day.AddDate(0, 0, 9), BankSource: "Bank1"},
+	}
+	overrides := []domain.Override{
+		{Action: domain.OverrideIgnore, BankUniqueIdentifier: "BANK003"},
+		{Action: domain.OverrideMatch, SystemTrxID: "SYS001", BankUniqueIdentifier: "BANK002"},
+	}
+
+	streamer := mock_usecase.NewMockTransactionStreamer(ctrl)
+	streamer.EXPECT().StreamSystemTransactions(gomock.Any(), gomock.Any(), start, end).Return(domain.SliceStream(systemTxs), nil)
+	streamer.EXPECT().StreamBankTransactions(gomock.Any(), gomock.Any(), start, end).Return(domain.SliceStream(bankTxs), nil)
+	uc := usecase.NewReconciliationUseCase(nil,
+		usecase.WithDatePartitioning(streamer),
+		usecase.WithSettlementLag(1, false),
+		usecase.WithOverrides(overrides),
+	)
+	got, err := uc.Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), start, end)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 1, got.ReconciliationSummary.MatchedTransactions)
+	assert.Equal(t, 3, got.ReconciliationSummary.TotalBankTransactionsProcessed)
+	assert.Equal(t, []domain.SystemTransaction{systemTxs[1]}, got.UnmatchedTransactions.SystemMissingFromBank)
+	assert.Equal(t, []domain.BankTransaction{bankTxs[1]}, got.UnmatchedTransactions.BankMissingFromSystem["Bank1"])
+	if assert.NotNil(t, got.IgnoredTransactions) {
+		assert.Equal(t, []domain.BankTransaction{bankTxs[2]}, got.IgnoredTransactions.BankTransactions)
+	}
+	if assert.Len(t, got.OverrideErrors, 1) {
+		assert.Equal(t, overrides[1], got.OverrideErrors[0].Override)
+	}
+}
+
+func TestReconciliationUseCase_Reconcile_DatePartitioningErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	day := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	unordered := []domain.SystemTransaction{
+		{TrxID: "SYS002", Amount: domain.MustParseDecimal("1"), Type: domain.TransactionTypeDebit, TransactionTime: day.AddDate(0, 0, 1)},
+		{TrxID: "SYS001", Amount: domain.MustParseDecimal("1"), Type: domain.TransactionTypeDebit, TransactionTime: day},
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(streamer *mock_usecase.MockTransactionStreamer)
+		wantErr string
+	}{
+		{
+			name: "system stream fails",
+			setup: func(streamer *mock_usecase.MockTransactionStreamer) {
+				streamer.EXPECT().StreamSystemTransactions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("disk full"))
+			},
+			wantErr: "could not get system transactions: disk full",
+		},
+		{
+			name: "stream out of date order",
+			setup: func(streamer *mock_usecase.MockTransactionStreamer) {
+				streamer.EXPECT().StreamSystemTransactions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.SliceStream(unordered), nil)
+				streamer.EXPECT().StreamBankTransactions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.SliceStream[domain.BankTransaction](nil), nil)
+			},
+			wantErr: "not in date order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamer := mock_usecase.NewMockTransactionStreamer(ctrl)
+			tt.setup(streamer)
+			_, err := usecase.NewReconciliationUseCase(nil, usecase.WithDatePartitioning(streamer)).Reconcile(context.Background(), namedSource("system.csv"), namedSources("bank.csv"), day, day.AddDate(0, 0, 5))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+// sortedByDate returns the bank transactions in date order, as a streamer yields them.
+func sortedByDate(txs []domain.BankTransaction) []domain.BankTransaction {
+	sorted := append([]domain.BankTransaction(nil), txs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return sorted
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// referenceConfidence is the confidence of a match on a quoted system transaction ID.
+const referenceConfidence = 1.0
+
+// defaultReferencePattern recognises system transaction IDs quoted as "trxID:<id>".
+var defaultReferencePattern = regexp.MustCompile(`(?i)trxID:\s*([\w./-]*\w)`)
+
+// ReferenceRule describes how a bank quotes system transaction IDs on its statements.
+// BankSource is matched against BankTransaction.BankSource and may be a glob pattern
+// (e.g. "statement_bank_A*.csv"). Each pattern extracts IDs from the description and the
+// reference fields: the first capturing group if it has one, otherwise the whole match.
+type ReferenceRule struct {
+	BankSource string
+	Patterns   []*regexp.Regexp
+}
+
+// NewReferenceRule compiles patterns into a rule for bankSource. Patterns match case-insensitively.
+func NewReferenceRule(bankSource string, patterns ...string) (ReferenceRule, error) {
+	rule := ReferenceRule{BankSource: bankSource}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return ReferenceRule{}, fmt.Errorf("invalid reference pattern %q: %w", pattern, err)
+		}
+		rule.Patterns = append(rule.Patterns, re)
+	}
+	return rule, nil
+}
+
+type referenceMatcher struct {
+	rules []ReferenceRule
+}
+
+// ReferenceMatcher pairs a bank transaction with the system transaction whose ID it quotes.
+// IDs quoted as "trxID:<id>" are always recognised; the first rule matching a bank source
+// adds its own patterns. A reference field holding nothing but an ID is recognised as well.
+// IDs are compared case-insensitively.
+func ReferenceMatcher(rules ...ReferenceRule) Matcher {
+	return referenceMatcher{rules: rules}
+}
+
+func (referenceMatcher) Name() string {
+	return "reference"
+}
+
+func (m referenceMatcher) Match(ctx context.Context, state *MatchState) error {
+	// Index unmatched system transactions by ID so each quoted reference is a single lookup
+	systemTxs := state.UnmatchedSystem()
+	byID := make(map[string][]int, len(systemTxs))
+	for i, sysTx := range systemTxs {
+		id := strings.ToUpper(sysTx.TrxID)
+		byID[id] = append(byID[id], i)
+	}
+
+	for _, bankTx := range state.UnmatchedBank() {
+		// Of all the system transactions quoted, match the first in date, amount and ID order
+		best, bestReference := -1, ""
+		for reference := range m.references(bankTx) {
+			for _, i := range byID[reference] {
+				if state.IsSystemMatched(systemTxs[i]) {
+					continue
+				}
+				if best < 0 || i < best {
+					best, bestReference = i, reference
+				}
+				break
+			}
+		}
+		if best >= 0 {
+			state.Match(systemTxs[best], bankTx, Evidence{Key: bestReference, Confidence: referenceConfidence})
+		}
+	}
+	return nil
+}
+
+// references returns the upper-cased system transaction IDs quoted by bankTx.
+func (m referenceMatcher) references(bankTx domain.BankTransaction) map[string]bool {
+	patterns := []*regexp.Regexp{defaultReferencePattern}
+	if rule, ok := m.ruleFor(bankTx.BankSource); ok {
+		patterns = append(patterns, rule.Patterns...)
+	}
+
+	references := make(map[string]bool)
+	extract := func(text string) {
+		for _, re := range patterns {
+			for _, match := range re.FindAllStringSubmatch(text, -1) {
+				id := match[0]
+				if len(match) > 1 {
+					id = match[1]
+				}
+				if id = strings.TrimSpace(id); id != "" {
+					references[strings.ToUpper(id)] = true
+				}
+			}
+		}
+	}
+
+	extract(bankTx.Description)
+	for _, field := range bankTx.ReferenceFields {
+		extract(field)
+		if id := strings.TrimSpace(field); id != "" {
+			references[strings.ToUpper(id)] = true
+		}
+	}
+	return references
+}
+
+func (m referenceMatcher) ruleFor(bankSource string) (ReferenceRule, bool) {
+	for _, rule := range m.rules {
+		if matchesSource(rule.BankSource, bankSource) {
+			return rule, true
+		}
+	}
+	return ReferenceRule{}, false
+}
+
+// matchesSource reports whether a rule for pattern applies to the statement bankSource:
+// the pattern matches it as a glob or, for statement names holding glob characters, equals
+// it. Fee and reference rules are looked up alike, the first rule to apply winning.
+func matchesSource(pattern, bankSource string) bool {
+	ok, _ := filepath.Match(pattern, bankSource)
+	return ok || pattern == bankSource
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// RunStore persists reconciliation runs and the reports of those that succeeded.
+//
+//go:generate mockgen -destination=mocks/mock_runs.go -source=runs.go RunStore
+type RunStore interface {
+	// CreateRun stores a new run with its parameters and inputs.
+	CreateRun(ctx context.Context, run domain.Run) error
+	// UpdateRun stores the status, error and timestamps of a run, along with its report
+	// once it succeeded (nil otherwise).
+	UpdateRun(ctx context.Context, run domain.Run, report *domain.ReconciliationReport) error
+	// GetRun returns the run with the given ID, or domain.ErrRunNotFound.
+	GetRun(ctx context.Context, id string) (domain.Run, error)
+	// ListRuns returns the most recently created runs first, at most limit of them
+	// (all of them when limit is zero).
+	ListRuns(ctx context.Context, limit int) ([]domain.Run, error)
+	// GetReport returns the report of a succeeded run, or domain.ErrRunNotFound.
+	GetReport(ctx context.Context, id string) (*domain.ReconciliationReport, error)
+}
+
+// ReconcileFunc performs the reconciliation of one run, typically by calling
+// ReconciliationUseCase.Reconcile on the run's inputs.
+type ReconcileFunc func(ctx context.Context) (*domain.ReconciliationReport, error)
+
+// RunUseCase records reconciliations that run in the background, so that their status
+// can be polled and their reports fetched later.
+type RunUseCase struct {
+	store RunStore
+	now   func() time.Time
+}
+
+// NewRunUseCase creates a new instance of the usecase.
+func NewRunUseCase(store RunStore) *RunUseCase {
+	return &RunUseCase{store: store, now: time.Now}
+}
+
+// Submit records a queued run with the given parameters and inputs and returns it with
+// its newly assigned ID.
+func (uc *RunUseCase) Submit(ctx context.Context, params domain.RunParameters, inputs []domain.RunInput) (domain.Run, error) {
+	id, err := newRunID()
+	if err != nil {
+		return domain.Run{}, err
+	}
+	run := domain.Run{
+		ID:         id,
+		Status:     domain.RunQueued,
+		Parameters: params,
+		Inputs:     inputs,
+		CreatedAt:  uc.now().UTC(),
+	}
+	if err := uc.store.CreateRun(ctx, run); err != nil {
+		return domain.Run{}, fmt.Errorf("could not save run: %w", err)
+	}
+	return run, nil
+}
+
+// Execute runs reconcile for a queued run, recording when it started and how it ended.
+// A failed reconciliation is recorded on the run; the returned error only reports a
+// failure to record it.
+func (uc *RunUseCase) Execute(ctx context.Context, run domain.Run, reconcile ReconcileFunc) (domain.Run, error) {
+	started := uc.now().UTC()
+	run.Status, run.StartedAt = domain.RunRunning, &started
+	// Record the run with a context of its own, so that an interrupted run is still recorded
+	if err := uc.store.UpdateRun(context.WithoutCancel(ctx), run, nil); err != nil {
+		return run, fmt.Errorf("could not save run %s: %w", run.ID, err)
+	}
+
+	var report *domain.ReconciliationReport
+	err := ctx.Err()
+	if err == nil {
+		report, err = reconcile(ctx)
+	}
+	if err != nil {
+		return uc.Fail(context.WithoutCancel(ctx), run, err.Error())
+	}
+
+	finished := uc.now().UTC()
+	run.Status, run.FinishedAt = domain.RunSucceeded, &finished
+	if err := uc.store.UpdateRun(context.WithoutCancel(ctx), run, report); err != nil {
+		return run, fmt.Errorf("could not save run %s: %w", run.ID, err)
+	}
+	return run, nil
+}
+
+// Fail records that a run ended without a report for the given reason.
+func (uc *RunUseCase) Fail(ctx context.Context, run domain.Run, reason string) (domain.Run, error) {
+	finished := uc.now().UTC()
+	run.Status, run.Error, run.FinishedAt = domain.RunFailed, reason, &finished
+	if err := uc.store.UpdateRun(ctx, run, nil); err != nil {
+		return run, fmt.Errorf("could not save run %s: %w", run.ID, err)
+	}
+	return run, nil
+}
+
+// FailInterrupted records every run still queued or running as failed. Runs do not survive
+// a restart of the process executing them, so it is called before new runs are accepted.
+func (uc *RunUseCase) FailInterrupted(ctx context.Context) (int, error) {
+	runs, err := uc.store.ListRuns(ctx, 0)
+	if err != nil {
+		return 0, fmt.Errorf("could not list runs: %w", err)
+	}
+	failed := 0
+	for _, run := range runs {
+		if run.Status.Finished() {
+			continue
+		}
+		if _, err := uc.Fail(ctx, run, "interrupted by a restart"); err != nil {
+			return failed, err
+		}
+		failed++
+	}
+	return failed, nil
+}
+
+// Get returns the run with the given ID.
+func (uc *RunUseCase) Get(ctx context.Context, id string) (domain.Run, error) {
+	return uc.store.GetRun(ctx, id)
+}
+
+// List returns the most recent runs first, at most limit of them.
+func (uc *RunUseCase) List(ctx context.Context, limit int) ([]domain.Run, error) {
+	return uc.store.ListRuns(ctx, limit)
+}
+
+// Report returns the report of a succeeded run.
+func (uc *RunUseCase) Report(ctx context.Context, id string) (*domain.ReconciliationReport, error) {
+	return uc.store.GetReport(ctx, id)
+}
+
+// newRunID returns a random run ID of 32 hex digits.
+func newRunID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("could not generate run ID: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+)
+
+// settlementWindow is how far a bank booking date may drift from the system transaction date.
+type settlementWindow struct {
+	days         int
+	businessDays bool      // count only Monday to Friday
+	periodStart  time.Time // first date of the period; transactions before it are carried-in open items
+}
+
+// startingOn returns the window for a period starting on start.
+func (w settlementWindow) startingOn(start time.Time) settlementWindow {
+	w.periodStart = dayOf(start)
+	return w
+}
+
+// contains reports whether a bank booking on bankDay is within the window around sysDay.
+func (w settlementWindow) contains(sysDay, bankDay time.Time) bool {
+	sysDay, bankDay = w.acrossPeriods(sysDay, bankDay)
+	if w.businessDays {
+		return abs(businessDaysBetween(sysDay, bankDay)) <= w.days
+	}
+	return abs(dayOffset(sysDay, bankDay)) <= w.days
+}
+
+// span returns how many calendar days the window reaches on either side of a date.
+func (w settlementWindow) span() int {
+	if w.businessDays {
+		// Every five business days step over a weekend, and a window may end on one
+		return w.days + 2*((w.days+4)/5) + 2
+	}
+	return w.days
+}
+
+// acrossPeriods moves an open item carried in from an earlier period to the first date of
+// the period when it is paired with a transaction of the period, so that the window also
+// covers the gap between the periods. Two carried items keep their own dates, so that items
+// left open long ago do not pair up with each other.
+func (w settlementWindow) acrossPeriods(sysDay, bankDay time.Time) (time.Time, time.Time) {
+	sysCarried, bankCarried := dayOf(sysDay).Before(w.periodStart), dayOf(bankDay).Before(w.periodStart)
+	switch {
+	case sysCarried && !bankCarried:
+		sysDay = w.periodStart
+	case bankCarried && !sysCarried:
+		bankDay = w.periodStart
+	}
+	return sysDay, bankDay
+}
+
+// day returns the date a transaction dated t is looked up on: its own date, or the first
+// date of the period for a carried-in open item. A pair within the window is never more
+// than span days apart on these dates.
+func (w settlementWindow) day(t time.Time) time.Time {
+	if d := dayOf(t); !d.Before(w.periodStart) {
+		return d
+	}
+	return w.periodStart
+}
+
+// offset returns the number of days from sysDay to bankDay that the window measures.
+func (w settlementWindow) offset(sysDay, bankDay time.Time) int {
+	return dayOffset(w.acrossPeriods(sysDay, bankDay))
+}
+
+// closestDate picks the bank booking date nearest to sysDay that is within the window and
+// satisfies accept. Equal distances prefer the bank booking after the system date, since
+// banks settle late rather than early.
+func (w settlementWindow) closestDate(sysDay time.Time, bankDays []time.Time, accept func(time.Time) bool) (time.Time, bool) {
+	var best time.Time
+	found := false
+	for _, bankDay := range bankDays {
+		if !w.contains(sysDay, bankDay) || !accept(bankDay) {
+			continue
+		}
+		if !found || closer(w.offset(sysDay, bankDay), w.offset(sysDay, best)) {
+			best, found = bankDay, true
+		}
+	}
+	return best, found
+}
+
+func closer(offset, than int) bool {
+	if abs(offset) != abs(than) {
+		return abs(offset) < abs(than)
+	}
+	return offset > than
+}
+
+// dayOf truncates t to its calendar date.
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayOffset returns the number of calendar days from a to b.
+func dayOffset(a, b time.Time) int {
+	return int(dayOf(b).Sub(dayOf(a)).Hours() / 24)
+}
+
+// businessDaysBetween returns the signed number of weekdays stepped over going from a to b.
+func businessDaysBetween(a, b time.Time) int {
+	from, to, sign := dayOf(a), dayOf(b), 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+	count := 0
+	for d := from.AddDate(0, 0, 1); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			count++
+		}
+	}
+	return sign * count
+}
+
+func sortedDays[T any](m map[time.Time]T) []time.Time {
+	days := make([]time.Time, 0, len(m))
+	for d := range m {
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"mini-reconciliation/internal/domain"
+)
+
+// onePercent is 1/100, used to turn percentages into factors.
+var onePercent = domain.NewDecimal(1, 2)
+
+// toleranceConfidence is the confidence of a match on close amounts, by how the difference is explained.
+var toleranceConfidence = map[domain.DiscrepancyReason]float64{
+	domain.DiscrepancyFeeDeducted:     0.7,
+	domain.DiscrepancyWithinTolerance: 0.6,
+}
+
+// FeeRule describes the transfer fee a bank deducts from transactions on its statements.
+// BankSource is matched against BankTransaction.BankSource and may be a glob pattern
+// (e.g. "statement_bank_A*.csv"). The fee is Fixed plus Percent of the system amount,
+// in the reporting currency.
+type FeeRule struct {
+	BankSource string
+	Fixed      domain.Decimal
+	Percent    domain.Decimal
+}
+
+// amountTolerance is how far apart a system and bank amount may be and still match.
+// The allowance is the larger of the absolute amount and the percentage of the system amount.
+type amountTolerance struct {
+	absolute domain.Decimal
+	percent  domain.Decimal
+}
+
+func (t amountTolerance) allowance(sysAmount domain.Decimal, currency domain.Currency) domain.Decimal {
+	relative := currency.Round(sysAmount.Abs().Mul(t.percent).Mul(onePercent))
+	if relative.Cmp(t.absolute) > 0 {
+		return relative
+	}
+	return t.absolute
+}
+
+type toleranceMatcher struct {
+	tolerance amountTolerance
+	fees      []FeeRule
+}
+
+// ToleranceMatcher pairs remaining transactions of the same type within the settlement
+// window whose amounts differ by the expected bank fee (reported as "fee_deducted") or by
+// no more than the larger of absolute and percent of the system amount (reported as
+// "within_tolerance"). The first fee rule matching a bank source applies.
+func ToleranceMatcher(absolute, percent domain.Decimal, fees ...FeeRule) Matcher {
+	return toleranceMatcher{
+		tolerance: amountTolerance{absolute: absolute.Abs(), percent: percent.Abs()},
+		fees:      fees,
+	}
+}
+
+func (toleranceMatcher) Name() string {
+	return "tolerance"
+}
+
+func (m toleranceMatcher) describe(params *domain.RunParameters) {
+	absolute, percent := m.tolerance.absolute, m.tolerance.percent
+	params.ToleranceAbsolute, params.TolerancePercent = &absolute, &percent
+}
+
+func (m toleranceMatcher) Match(ctx context.Context, state *MatchState) error {
+	index := newToleranceIndex(state)
+	span := state.settlement.span()
+	for _, sysTx := range state.UnmatchedSystem() {
+		sysAmount := state.SystemAmount(sysTx)
+		sysDay := state.settlement.day(sysTx.TransactionTime)
+		reach := m.reach(sysAmount, state.ReportingCurrency())
+
+		var best *toleranceCandidate
+		for offset := -span; offset <= span; offset++ {
+			bankDay := sysDay.AddDate(0, 0, offset)
+			if !state.WithinSettlementWindow(sysDay, bankDay) {
+				continue
+			}
+			for _, entry := range index.near(sysTx.Type, bankDay, sysAmount, reach) {
+				if state.IsBankMatched(entry.tx) || !state.WithinSettlementWindow(sysTx.TransactionTime, entry.tx.Date) {
+					continue
+				}
+				reason, ok := m.classify(sysTx, entry.tx, sysAmount, entry.amount, state.ReportingCurrency())
+				if !ok {
+					continue
+				}
+				candidate := toleranceCandidate{
+					bankTx: entry.tx,
+					reason: reason,
+					diff:   sysAmount.Sub(entry.amount).Abs(),
+					offset: offset,
+					order:  entry.order,
+				}
+				if best == nil || candidate.better(*best) {
+					best = &candidate
+				}
+			}
+		}
+
+		if best != nil {
+			state.Match(sysTx, best.bankTx, Evidence{
+				Key:        fmt.Sprintf("%s-%s~%s", sysTx.Type, sysAmount, state.BankAmount(best.bankTx)),
+				Confidence: toleranceConfidence[best.reason],
+				Reason:     best.reason,
+			})
+		}
+	}
+	return nil
+}
+
+// reach bounds how far a bank amount may be from sysAmount and still match: the allowance
+// plus the largest fee any bank deducts from it.
+func (m toleranceMatcher) reach(sysAmount domain.Decimal, currency domain.Currency) domain.Decimal {
+	var maxFee domain.Decimal
+	for _, rule := range m.fees {
+		if fee := rule.fee(sysAmount, currency); fee.Cmp(maxFee) > 0 {
+			maxFee = fee
+		}
+	}
+	return m.tolerance.allowance(sysAmount, currency).Add(maxFee)
+}
+
+// toleranceIndex holds the unmatched bank transactions by type and booking date, each date
+// ordered by reporting amount, so that a system transaction is only compared with the bank
+// transactions within its settlement window and within reach of its amount.
+type toleranceIndex map[domain.TransactionType]map[time.Time][]toleranceEntry
+
+type toleranceEntry struct {
+	tx     domain.BankTransaction
+	amount domain.Decimal // in the reporting currency
+	order  int            // position among the unmatched bank transactions
+}
+
+func newToleranceIndex(state *MatchState) toleranceIndex {
+	index := make(toleranceIndex)
+	for i, tx := range state.UnmatchedBank() {
+		byDay := index[tx.Type]
+		if byDay == nil {
+			byDay = make(map[time.Time][]toleranceEntry)
+			index[tx.Type] = byDay
+		}
+		day := state.settlement.day(tx.Date)
+		byDay[day] = append(byDay[day], toleranceEntry{tx: tx, amount: state.BankAmount(tx), order: i})
+	}
+	for _, byDay := range index {
+		for _, entries := range byDay {
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].amount.Cmp(entries[j].amount) < 0 })
+		}
+	}
+	return index
+}
+
+// near returns the bank transactions of the type booked on day whose amount is within
+// reach of amount.
+func (idx toleranceIndex) near(txType domain.TransactionType, day time.Time, amount, reach domain.Decimal) []toleranceEntry {
+	entries := idx[txType][day]
+	low, high := amount.Sub(reach), amount.Add(reach)
+	from := sort.Search(len(entries), func(i int) bool { return entries[i].amount.Cmp(low) >= 0 })
+	to := sort.Search(len(entries), func(i int) bool { return entries[i].amount.Cmp(high) > 0 })
+	return entries[from:to]
+}
+
+// expectedFee returns the fee the bank that issued bankSource deducts from sysAmount.
+func (m toleranceMatcher) expectedFee(bankSource string, sysAmount domain.Decimal, currency domain.Currency) (domain.Decimal, bool) {
+	for _, rule := range m.fees {
+		if matchesSource(rule.BankSource, bankSource) {
+			return rule.fee(sysAmount, currency), true
+		}
+	}
+	return domain.Decimal{}, false
+}
+
+// fee returns the fee the rule deducts from sysAmount.
+func (r FeeRule) fee(sysAmount domain.Decimal, currency domain.Currency) domain.Decimal {
+	return currency.Round(r.Fixed.Add(sysAmount.Abs().Mul(r.Percent).Mul(onePercent)))
+}
+
+// toleranceCandidate is a bank transaction that may be paired with a system transaction
+// despite a difference in amount.
+type toleranceCandidate struct {
+	bankTx domain.BankTransaction
+	reason domain.DiscrepancyReason
+	diff   domain.Decimal // |system - bank| in the reporting currency
+	offset int
+	order  int // position among the unmatched bank transactions, breaking ties
+}
+
+// better reports whether c should be preferred over o: explained fees first, then the
+// smaller amount difference, then the closer booking date, then the earlier in date,
+// amount and ID order.
+func (c toleranceCandidate) better(o toleranceCandidate) bool {
+	if c.reason != o.reason {
+		return c.reason == domain.DiscrepancyFeeDeducted
+	}
+	if cmp := c.diff.Cmp(o.diff); cmp != 0 {
+		return cmp < 0
+	}
+	if c.offset != o.offset {
+		return closer(c.offset, o.offset)
+	}
+	return c.order < o.order
+}
+
+// classify decides whether the differing amounts of a same-type pair are
+// close enough to match, and why. A bank receives less than the system amount on credits and pays out
+// more on debits when it deducts a fee.
+func (m toleranceMatcher) classify(sysTx domain.SystemTransaction, bankTx domain.BankTransaction, sysAmount, bankAmount domain.Decimal, currency domain.Currency) (domain.DiscrepancyReason, bool) {
+	// Equal amounts are left to exact and group matching
+	if sysAmount.Equal(bankAmount) {
+		return "", false
+	}
+	allowance := m.tolerance.allowance(sysAmount, currency)
+
+	if fee, ok := m.expectedFee(bankTx.BankSource, sysAmount, currency); ok && !fee.IsZero() {
+		deducted := sysAmount.Sub(bankAmount)
+		if sysTx.Type == domain.TransactionTypeDebit {
+			deducted = deducted.Neg()
+		}
+		if deducted.Sign() > 0 && deducted.Sub(fee).Abs().Cmp(allowance) <= 0 {
+			return domain.DiscrepancyFeeDeducted, true
+		}
+	}
+
+	if sysAmount.Sub(bankAmount).Abs().Cmp(allowance) <= 0 {
+		return domain.DiscrepancyWithinTolerance, true
+	}
+	return "", false
+}